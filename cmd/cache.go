@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jvalecillos/jira-metrics/pkg/cache"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// defaultCacheTTL is how long cached issues are used before revalidating them
+const defaultCacheTTL = 24 * time.Hour
+
+// issueCache is opened once and flushed when the command finishes
+var issueCache *cache.FileStore
+
+// cacheCmd represents the cache command
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manages the local cache of JIRA issues",
+}
+
+// cacheStatsCmd represents the cache stats command
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Shows the content of the issue cache",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		store, err := openIssueCache()
+		if err != nil {
+			return err
+		}
+
+		stats := store.Stats(cacheTTL())
+
+		fmt.Printf("Path:    %s\n", stats.Path)
+		fmt.Printf("Issues:  %d (%d expired, TTL %s)\n", stats.Entries, stats.Expired, cacheTTL())
+		fmt.Printf("Size:    %d bytes\n", stats.Size)
+		if stats.Entries > 0 {
+			fmt.Printf("Oldest:  %s\n", stats.Oldest.Format(time.RFC3339))
+			fmt.Printf("Newest:  %s\n", stats.Newest.Format(time.RFC3339))
+		}
+		return nil
+	},
+}
+
+// cacheClearCmd represents the cache clear command
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Removes all the cached issues",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		store, err := openIssueCache()
+		if err != nil {
+			return err
+		}
+
+		if err := store.Clear(); err != nil {
+			return err
+		}
+
+		fmt.Printf("Issue cache cleared\n")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheStatsCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+}
+
+// cacheEnabled is false when CACHE_DISABLED is set
+func cacheEnabled() bool {
+	return !viper.GetBool("CACHE_DISABLED")
+}
+
+// cacheTTL reads CACHE_TTL, a zero TTL keeps issues until the cache is cleared
+func cacheTTL() time.Duration {
+	if viper.IsSet("CACHE_TTL") {
+		return viper.GetDuration("CACHE_TTL")
+	}
+	return defaultCacheTTL
+}
+
+// openIssueCache opens the issue cache in CACHE_DIR or the user cache directory
+func openIssueCache() (*cache.FileStore, error) {
+
+	if issueCache != nil {
+		return issueCache, nil
+	}
+
+	dir := viper.GetString("CACHE_DIR")
+	if dir == "" {
+		var err error
+		if dir, err = cache.DefaultDir(); err != nil {
+			return nil, errors.Wrap(err, "error finding the cache directory, set CACHE_DIR")
+		}
+	}
+
+	store, err := cache.Open(dir, cacheTTL())
+	if err != nil {
+		return nil, err
+	}
+
+	issueCache = store
+	return issueCache, nil
+}
+
+// flushIssueCache persists the issues cached during the command, if any
+func flushIssueCache() error {
+	if issueCache == nil {
+		return nil
+	}
+	return issueCache.Flush()
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jvalecillos/jira-metrics/pkg/helper"
+	"github.com/jvalecillos/jira-metrics/pkg/jira"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// disciplinesCmd represents the disciplines command
+var disciplinesCmd = &cobra.Command{
+	Use:   "disciplines",
+	Short: "Inspects the discipline classification of issues",
+}
+
+// disciplinesExplainCmd represents the disciplines explain command
+var disciplinesExplainCmd = &cobra.Command{
+	Use:   "explain ISSUE-KEY",
+	Short: "Shows which discipline rule classifies an issue",
+	Long: `Evaluates all the discipline rules for an issue, showing the values of
+the matched fields and which rule decided the discipline.
+
+Example: jira-metrics disciplines explain STR-1234`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		disciplineHelper, err := newDisciplineHelper()
+		if err != nil {
+			return err
+		}
+
+		jc, err := newJiraClient(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		issuesSrv, _ := jc.Issues()
+
+		details, err := issuesSrv.Get(cmd.Context(), args[0])
+		if err != nil {
+			return errors.Wrapf(err, "error getting issue %s", args[0])
+		}
+
+		issue := jira.Issue{
+			Key:      details.Key,
+			Summary:  details.Fields.Summary,
+			TypeName: details.Fields.Issuetype.Name,
+		}
+
+		subject := helper.NewDisciplineSubject(issue, func() (*jira.SimpleIssue, error) {
+			return details, nil
+		})
+
+		result, err := disciplineHelper.Explain(subject)
+		if err != nil {
+			return errors.Wrapf(err, "error classifying issue %s", args[0])
+		}
+
+		fmt.Printf("%s: %s\n\n", details.Key, details.Fields.Summary)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "RULE\tFIELD\tVALUES\tMATCH")
+		for _, eval := range result.Evaluations {
+			match := "-"
+			if eval.Matched {
+				match = fmt.Sprintf("%s (weight %g)", eval.Discipline, eval.Rule.Weight)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", eval.Rule.Name, eval.Rule.Field, strings.Join(eval.Values, ", "), match)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		fmt.Printf("\nDiscipline: %s (decided by %s)\n", result.Discipline, result.Rule)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(disciplinesCmd)
+	disciplinesCmd.AddCommand(disciplinesExplainCmd)
+}
+
+// newDisciplineHelper reads the discipline rules from the configuration, the
+// default rules are used when none are configured
+func newDisciplineHelper() (helper.DisciplineHelper, error) {
+
+	var rules []helper.DisciplineRule
+	if err := viper.UnmarshalKey("DISCIPLINE_RULES", &rules); err != nil {
+		return helper.DisciplineHelper{}, errors.Wrap(err, "error reading DISCIPLINE_RULES")
+	}
+
+	disciplineHelper, err := helper.NewDisciplineHelper(
+		rules,
+		viper.GetString("DISCIPLINE_MODE"),
+		viper.GetString("DISCIPLINE_FALLBACK"),
+	)
+	if err != nil {
+		return helper.DisciplineHelper{}, errors.Wrap(err, "error reading discipline rules")
+	}
+
+	return disciplineHelper, nil
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/jvalecillos/jira-metrics/pkg/jira"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var allFields bool
+
+// fieldsCmd represents the fields command
+var fieldsCmd = &cobra.Command{
+	Use:   "fields",
+	Short: "Inspects the JIRA fields",
+}
+
+// fieldsDiscoverCmd represents the fields discover command
+var fieldsDiscoverCmd = &cobra.Command{
+	Use:   "discover",
+	Short: "Suggests the custom field configuration for this JIRA instance",
+	Long: `Fetches the fields of the JIRA instance and looks up the custom fields
+used for story points, epic links and disciplines by their usual names,
+printing the configuration to add to .jira-metrics.yaml.
+
+Example: jira-metrics fields discover [--all]`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		jc, err := newJiraClient(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		fieldsSrv, _ := jc.FieldDefinitions()
+
+		fields, err := fieldsSrv.Get(cmd.Context())
+		if err != nil {
+			return errors.Wrap(err, "error getting JIRA fields")
+		}
+
+		names := make(map[string]string, len(fields))
+		for _, f := range fields {
+			names[f.ID] = f.Name
+		}
+
+		suggested := jira.SuggestFieldMapping(fields)
+
+		fmt.Println("# Suggested configuration")
+		printFieldSuggestion("JIRA_FIELD_STORY_POINTS", suggested.StoryPoints, names)
+		printFieldSuggestion("JIRA_FIELD_EPIC_LINK", suggested.EpicLink, names)
+		printFieldSuggestion("JIRA_FIELD_DISCIPLINE", suggested.Discipline, names)
+
+		if !allFields {
+			return nil
+		}
+
+		sort.Slice(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
+
+		fmt.Println()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tTYPE\tNAME")
+		for _, f := range fields {
+			if f.Custom {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", f.ID, f.Schema.Type, f.Name)
+			}
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(fieldsCmd)
+	fieldsCmd.AddCommand(fieldsDiscoverCmd)
+
+	// flags and configuration settings.
+	fieldsDiscoverCmd.Flags().BoolVar(&allFields, "all", false, "List all the custom fields too")
+}
+
+// printFieldSuggestion prints a configuration line for a field, commented out when not found
+func printFieldSuggestion(key, id string, names map[string]string) {
+	if id == "" {
+		fmt.Printf("# %s: not found, run with --all to pick it manually\n", key)
+		return
+	}
+	fmt.Printf("%s: %s # %s\n", key, id, names[id])
+}
@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jvalecillos/jira-metrics/pkg/helper"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var forecastUnit string
+var forecastRemaining float64
+var forecastBy string
+var forecastSprints int
+var forecastSprintDays int
+var forecastSimulations int
+var forecastSeed int64
+
+// forecastCmd represents the forecast command
+var forecastCmd = &cobra.Command{
+	Use:   "forecast",
+	Short: "Forecasts delivery with Monte Carlo simulations of past Sprints",
+	Long: `Runs Monte Carlo simulations picking at random the completed points (or
+issues) of the selected Sprints to forecast how many Sprints it takes to
+complete the --remaining amount, or how much gets completed within --sprints
+Sprints or by the --by date. Results are given with 50%, 85% and 95% confidence.
+
+Example: jira-metrics forecast --project 123 --last 10 --remaining 120
+         jira-metrics forecast --project 123 --last 10 --by 2022-03-31 --unit issues
+         jira-metrics forecast --project 123 --year 2021 --sprints 4 --seed 42`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+
+		if err := selection.validate(); err != nil {
+			return err
+		}
+
+		if reportFormat != formatTable && reportFormat != formatJSON {
+			return errors.Errorf("unknown format %q, expected %s or %s", reportFormat, formatTable, formatJSON)
+		}
+
+		// the unit defaults to the metrics mode of the board
+		if !cmd.Flags().Changed("unit") {
+			measure, err := newMeasure()
+			if err != nil {
+				return err
+			}
+			forecastUnit = measure.Mode()
+		}
+
+		unit, err := parseForecastUnit(forecastUnit)
+		if err != nil {
+			return err
+		}
+		forecastUnit = unit
+
+		questions := 0
+		for _, flag := range []string{"remaining", "by", "sprints"} {
+			if cmd.Flags().Changed(flag) {
+				questions++
+			}
+		}
+		if questions != 1 {
+			return errors.New("exactly one of --remaining, --by or --sprints is required")
+		}
+
+		if cmd.Flags().Changed("remaining") && forecastRemaining <= 0 {
+			return errors.New("--remaining must be a positive number")
+		}
+
+		if cmd.Flags().Changed("sprints") && forecastSprints < 1 {
+			return errors.New("--sprints must be a positive number")
+		}
+
+		if forecastSprintDays < 1 {
+			return errors.New("--sprint-days must be a positive number")
+		}
+
+		if cmd.Flags().Changed("by") {
+			by, err := time.ParseInLocation(dateLayout, forecastBy, time.Local)
+			if err != nil {
+				return errors.Wrap(err, "invalid --by date, expected YYYY-MM-DD")
+			}
+			forecastSprints = int(time.Until(by).Hours() / 24 / float64(forecastSprintDays))
+			if forecastSprints < 1 {
+				return errors.Errorf("--by date is less than one Sprint (%d days) away", forecastSprintDays)
+			}
+		}
+
+		if !cmd.Flags().Changed("seed") {
+			forecastSeed = time.Now().UnixNano()
+		}
+
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		ctx := cmd.Context()
+
+		jc, err := newJiraClient(ctx)
+		if err != nil {
+			return err
+		}
+
+		sprintNames, err := newSprintNameHelper()
+		if err != nil {
+			return err
+		}
+
+		reports, err := fetchSelectedReports(ctx, jc, sprintNames)
+		if err != nil {
+			return err
+		}
+
+		samples, err := helper.ThroughputSamples(reports, forecastUnit)
+		if err != nil {
+			return err
+		}
+
+		forecaster, err := helper.NewForecaster(samples, forecastSimulations, forecastSeed)
+		if err != nil {
+			return err
+		}
+
+		pf, err := newPointsFormat()
+		if err != nil {
+			return err
+		}
+
+		var results []helper.ForecastResult
+		var column string
+
+		if forecastRemaining > 0 {
+			results, err = forecaster.SprintsToFinish(forecastRemaining)
+			if err != nil {
+				return err
+			}
+			column = "SPRINTS"
+		} else {
+			results = forecaster.DeliveredIn(forecastSprints)
+			column = fmt.Sprintf("%s IN %d SPRINTS", forecastUnit, forecastSprints)
+		}
+
+		if reportFormat == formatJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(struct {
+				Unit        string                  `json:"unit"`
+				Remaining   float64                 `json:"remaining,omitempty"`
+				Sprints     int                     `json:"sprints,omitempty"`
+				Samples     []float64               `json:"samples"`
+				Simulations int                     `json:"simulations"`
+				Seed        int64                   `json:"seed"`
+				Results     []helper.ForecastResult `json:"results"`
+			}{forecastUnit, forecastRemaining, forecastSprints, samples, forecastSimulations, forecastSeed, results})
+		}
+
+		formatted := make([]string, len(samples))
+		for i, sample := range samples {
+			formatted[i] = pf.Format(sample)
+		}
+
+		fmt.Printf("%d simulations of %d Sprints (%s per Sprint: %s), seed %d\n",
+			forecastSimulations, len(samples), forecastUnit, strings.Join(formatted, ", "), forecastSeed)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		if forecastRemaining > 0 {
+			fmt.Fprintf(w, "CONFIDENCE\t%s\tFINISHED BY\n", column)
+			for _, r := range results {
+				// capped simulations didn't finish, there's no date to give
+				if r.Capped {
+					fmt.Fprintf(w, "%.0f%%\tmore than %g\t-\n", r.Confidence*100, r.Value)
+					continue
+				}
+				finished := time.Now().AddDate(0, 0, int(r.Value)*forecastSprintDays)
+				fmt.Fprintf(w, "%.0f%%\t%g\t%s\n", r.Confidence*100, r.Value, finished.Format(dateLayout))
+			}
+		} else {
+			fmt.Fprintf(w, "CONFIDENCE\t%s\n", column)
+			for _, r := range results {
+				fmt.Fprintf(w, "%.0f%%\t%s\n", r.Confidence*100, pf.Format(r.Value))
+			}
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(forecastCmd)
+
+	// flags and configuration settings.
+	addSprintSelectionFlags(forecastCmd)
+	forecastCmd.Flags().IntVarP(&concurrency, "concurrency", "c", 1, "Number of Sprint reports fetched from JIRA in parallel")
+	forecastCmd.Flags().StringVarP(&reportFormat, "format", "f", formatTable, "Output format: table or json")
+	forecastCmd.Flags().StringVar(&forecastUnit, "unit", "", "Throughput unit: points or issues (default the metrics mode of the board)")
+	forecastCmd.Flags().Float64Var(&forecastRemaining, "remaining", 0, "Forecast the Sprints needed to complete this amount")
+	forecastCmd.Flags().StringVar(&forecastBy, "by", "", "Forecast the amount completed by this date (YYYY-MM-DD)")
+	forecastCmd.Flags().IntVar(&forecastSprints, "sprints", 0, "Forecast the amount completed within this number of Sprints")
+	forecastCmd.Flags().IntVar(&forecastSprintDays, "sprint-days", 14, "Length of a Sprint in days, for dates")
+	forecastCmd.Flags().IntVar(&forecastSimulations, "simulations", 10000, "Number of simulations")
+	forecastCmd.Flags().Int64Var(&forecastSeed, "seed", 0, "Seed of the simulations, for reproducible results (random by default)")
+}
+
+// parseForecastUnit validates the throughput unit, ignoring its case
+func parseForecastUnit(unit string) (string, error) {
+	unit = strings.ToLower(unit)
+	if unit != helper.ForecastPoints && unit != helper.ForecastIssues {
+		return "", errors.Errorf("unknown unit %q, expected %s or %s", unit, helper.ForecastPoints, helper.ForecastIssues)
+	}
+	return unit, nil
+}
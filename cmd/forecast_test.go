@@ -0,0 +1,29 @@
+package cmd
+
+import "testing"
+
+func TestParseForecastUnit(t *testing.T) {
+
+	tests := []struct {
+		unit    string
+		want    string
+		wantErr bool
+	}{
+		{unit: "points", want: "points"},
+		{unit: "Issues", want: "issues"},
+		{unit: "POINTS", want: "points"},
+		{unit: "days", wantErr: true},
+		{unit: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseForecastUnit(tt.unit)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseForecastUnit(%q) error = %v, wantErr %v", tt.unit, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseForecastUnit(%q) = %q, want %q", tt.unit, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jvalecillos/jira-metrics/pkg/jira"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"golang.org/x/oauth2"
+)
+
+const (
+	authBasic  = "basic"
+	authBearer = "bearer"
+	authOAuth2 = "oauth2"
+
+	// Atlassian OAuth 2.0 (3LO) endpoints
+	atlassianAuthURL  = "https://auth.atlassian.com/authorize"
+	atlassianTokenURL = "https://auth.atlassian.com/oauth/token"
+	atlassianAudience = "api.atlassian.com"
+)
+
+// newJiraClient creates the JIRA client from the configuration, along with
+// the options specific to the command
+func newJiraClient(ctx context.Context, extra ...jira.Option) (*jira.Jira, error) {
+
+	auth, err := newJiraAuthenticator(ctx, viper.GetString("JIRA_AUTH"))
+	if err != nil {
+		return nil, errors.Wrap(err, "error setting up JIRA authentication")
+	}
+
+	opts := []jira.Option{
+		jira.WithRetry(newRetryPolicy()),
+		jira.WithRateLimit(rateLimit()),
+		jira.WithFieldMapping(newFieldMapping()),
+		auth,
+	}
+
+	opts = append(opts, extra...)
+
+	if cacheEnabled() {
+		store, err := openIssueCache()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, jira.WithIssueCache(store, cacheTTL()))
+	}
+
+	jc, err := jira.New(jira.Config{
+		Username:       viper.GetString("JIRA_USERNAME"),
+		Password:       viper.GetString("JIRA_TOKEN"),
+		EndpointPrefix: viper.GetString("JIRA_ENDPOINT_PREFIX"),
+		BrowsePrefix:   viper.GetString("JIRA_BROWSE_PREFIX"),
+	}, opts...)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating JIRA client")
+	}
+
+	return jc, nil
+}
+
+// newJiraAuthenticator returns the option setting up the configured
+// authentication method, nil for the default basic authentication
+func newJiraAuthenticator(ctx context.Context, method string) (jira.Option, error) {
+	switch method {
+	case "", authBasic:
+		// https://support.atlassian.com/atlassian-account/docs/manage-api-tokens-for-your-atlassian-account/
+		return nil, nil
+	case authBearer:
+		// https://confluence.atlassian.com/enterprise/using-personal-access-tokens-1026032365.html
+		token := viper.GetString("JIRA_TOKEN")
+		if token == "" {
+			return nil, errors.New("JIRA_TOKEN with a personal access token is required for bearer authentication")
+		}
+		return jira.WithAuthenticator(jira.BearerToken{Token: token}), nil
+	case authOAuth2:
+		// https://developer.atlassian.com/cloud/jira/platform/oauth-2-3lo-apps/
+		source, err := newOAuth2TokenSource(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return jira.WithAuthenticator(jira.OAuth2{Source: source}), nil
+	default:
+		return nil, errors.Errorf("unknown JIRA_AUTH %q, expected %s, %s or %s", method, authBasic, authBearer, authOAuth2)
+	}
+}
+
+// newOAuth2TokenSource returns a token source for the configured Atlassian
+// OAuth 2.0 app, asking for an authorization code the first time
+func newOAuth2TokenSource(ctx context.Context) (oauth2.TokenSource, error) {
+
+	config := &oauth2.Config{
+		ClientID:     viper.GetString("JIRA_OAUTH2_CLIENT_ID"),
+		ClientSecret: viper.GetString("JIRA_OAUTH2_CLIENT_SECRET"),
+		RedirectURL:  viper.GetString("JIRA_OAUTH2_REDIRECT_URL"),
+		Scopes:       []string{"read:jira-work", "read:jira-user", "offline_access"},
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  atlassianAuthURL,
+			TokenURL: atlassianTokenURL,
+		},
+	}
+
+	if config.ClientID == "" || config.ClientSecret == "" {
+		return nil, errors.New("JIRA_OAUTH2_CLIENT_ID and JIRA_OAUTH2_CLIENT_SECRET are required for oauth2 authentication")
+	}
+
+	if viper.IsSet("JIRA_OAUTH2_SCOPES") {
+		config.Scopes = viper.GetStringSlice("JIRA_OAUTH2_SCOPES")
+	}
+
+	tokenFile := viper.GetString("JIRA_OAUTH2_TOKEN_FILE")
+	if tokenFile == "" {
+		tokenFile = "jira_token.json"
+	}
+
+	if _, err := os.Stat(tokenFile); os.IsNotExist(err) {
+		if err := authorizeOAuth2(ctx, config, tokenFile); err != nil {
+			return nil, err
+		}
+	}
+
+	return jira.FileTokenSource(ctx, config, tokenFile)
+}
+
+// authorizeOAuth2 asks the user to authorize the app in the browser and saves the resulting token
+func authorizeOAuth2(ctx context.Context, config *oauth2.Config, tokenFile string) error {
+
+	authURL := config.AuthCodeURL("state-token",
+		oauth2.SetAuthURLParam("audience", atlassianAudience),
+		oauth2.SetAuthURLParam("prompt", "consent"),
+	)
+	fmt.Printf("Go to the following link in your browser then type the "+
+		"authorization code: \n%v\n", authURL)
+
+	var authCode string
+	if _, err := fmt.Scan(&authCode); err != nil {
+		return errors.Wrap(err, "unable to read authorization code")
+	}
+
+	tok, err := config.Exchange(ctx, authCode)
+	if err != nil {
+		return errors.Wrap(err, "unable to retrieve token from web")
+	}
+
+	fmt.Printf("Saving credential file to: %s\n", tokenFile)
+	return jira.SaveToken(tokenFile, tok)
+}
+
+// newFieldMapping reads the custom field IDs from the configuration, the
+// default ones are used for the fields not configured
+func newFieldMapping() jira.FieldMapping {
+	return jira.FieldMapping{
+		StoryPoints: viper.GetString("JIRA_FIELD_STORY_POINTS"),
+		EpicLink:    viper.GetString("JIRA_FIELD_EPIC_LINK"),
+		Discipline:  viper.GetString("JIRA_FIELD_DISCIPLINE"),
+	}
+}
+
+// defaultRateLimit is the maximum number of requests per second sent to JIRA
+const defaultRateLimit = 10
+
+// rateLimit reads JIRA_RATE_LIMIT, 0 disables the rate limit
+func rateLimit() float64 {
+	if viper.IsSet("JIRA_RATE_LIMIT") {
+		return viper.GetFloat64("JIRA_RATE_LIMIT")
+	}
+	return defaultRateLimit
+}
+
+// newRetryPolicy reads the retry policy for JIRA requests from the configuration,
+// JIRA_RETRY_MAX_ATTEMPTS set to 1 disables retries
+func newRetryPolicy() jira.RetryPolicy {
+	policy := jira.DefaultRetryPolicy()
+
+	if viper.IsSet("JIRA_RETRY_MAX_ATTEMPTS") {
+		policy.MaxAttempts = viper.GetInt("JIRA_RETRY_MAX_ATTEMPTS")
+	}
+	if viper.IsSet("JIRA_RETRY_BASE_DELAY") {
+		policy.BaseDelay = viper.GetDuration("JIRA_RETRY_BASE_DELAY")
+	}
+	if viper.IsSet("JIRA_RETRY_MAX_DELAY") {
+		policy.MaxDelay = viper.GetDuration("JIRA_RETRY_MAX_DELAY")
+	}
+
+	policy.Logf = func(format string, args ...interface{}) {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
+	}
+
+	return policy
+}
+
+const (
+	backendGreenhopper = "greenhopper"
+	backendAgile       = "agile"
+)
+
+// newJiraBackend returns the services for listing Sprints and getting their
+// reports, either from the private Greenhopper endpoints (default) or the
+// official Jira Agile REST API
+func newJiraBackend(jc *jira.Jira, backend string) (jira.SprintLister, jira.ReportGetter, error) {
+	switch backend {
+	case "", backendGreenhopper:
+		sprintList, _ := jc.Sprints()
+		report, _ := jc.Report()
+		return sprintList, report, nil
+	case backendAgile:
+		sprintList, _ := jc.AgileSprints()
+		report, _ := jc.AgileReport()
+		return sprintList, report, nil
+	default:
+		return nil, nil, errors.Errorf("unknown JIRA_BACKEND %q, expected %q or %q", backend, backendGreenhopper, backendAgile)
+	}
+}
+
+// errorHint returns an actionable message for the known JIRA API errors
+func errorHint(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case jira.IsUnauthorized(err):
+		return "JIRA rejected the credentials, check JIRA_AUTH and JIRA_USERNAME/JIRA_TOKEN " +
+			"(https://id.atlassian.com/manage-profile/security/api-tokens) " +
+			"or remove JIRA_OAUTH2_TOKEN_FILE to authorize again"
+	case jira.IsForbidden(err):
+		return "the JIRA user can't access this resource, check its permissions on the board and project"
+	case jira.IsNotFound(err):
+		return "JIRA couldn't find the resource, check the board ID, the Sprint IDs and JIRA_ENDPOINT_PREFIX"
+	case jira.IsRateLimited(err):
+		return "JIRA is rate limiting the requests, try again later or raise JIRA_RETRY_MAX_ATTEMPTS"
+	case jira.IsHTML(err):
+		return "JIRA answered with a web page instead of the API, check JIRA_ENDPOINT_PREFIX " +
+			"and whether a login or SSO proxy is in front of JIRA"
+	}
+	return ""
+}
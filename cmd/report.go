@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jvalecillos/jira-metrics/pkg/helper"
+	"github.com/jvalecillos/jira-metrics/pkg/jira"
+	"github.com/jvalecillos/jira-metrics/pkg/sink"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var reportFormat string
+var reportSinks []string
+var reportOutputDir string
+var metricsMode string
+
+// reportCmd represents the report command
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Analyses Sprint reports across several Sprints",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// keeping the --timeout of the root command
+		rootCmd.PersistentPreRun(cmd, args)
+
+		if err := selection.validate(); err != nil {
+			return err
+		}
+		if reportFormat != formatTable && reportFormat != formatJSON {
+			return errors.Errorf("unknown format %q, expected %s or %s", reportFormat, formatTable, formatJSON)
+		}
+
+		// sinks and output directory default to the configuration
+		if !cmd.Flags().Changed("sink") {
+			reportSinks = viper.GetStringSlice("REPORT_SINKS")
+		}
+		if !cmd.Flags().Changed("output-dir") && viper.IsSet("REPORT_OUTPUT_DIR") {
+			reportOutputDir = viper.GetString("REPORT_OUTPUT_DIR")
+		}
+		return sink.ValidateTypes(reportSinks)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+}
+
+// addReportFlags adds the flags shared by the report commands
+func addReportFlags(cmd *cobra.Command) {
+	addSprintSelectionFlags(cmd)
+	cmd.Flags().IntVarP(&concurrency, "concurrency", "c", 1, "Number of Sprint reports fetched from JIRA in parallel")
+	cmd.Flags().StringVarP(&reportFormat, "format", "f", formatTable, "Output format: table or json")
+	cmd.Flags().StringVar(&metricsMode, "metrics", "", "Measure issues by points or count them with issues (default METRICS_MODE)")
+	cmd.Flags().StringSliceVar(&reportSinks, "sink", nil, "Also write the results to these sinks: sheet, csv or json (default REPORT_SINKS)")
+	cmd.Flags().StringVar(&reportOutputDir, "output-dir", ".", "Directory of the csv and json sinks (default REPORT_OUTPUT_DIR)")
+}
+
+// fetchSelectedReports gets the reports of the selected Sprints or, without
+// an explicit selection, of all the closed Sprints of the year. Reports are
+// sorted from the oldest to the newest Sprint.
+func fetchSelectedReports(
+	ctx context.Context,
+	jc *jira.Jira,
+	sprintNames helper.SprintNameHelper,
+) ([]jira.ReportResponse, error) {
+
+	sprintLister, reportGetter, err := newJiraBackend(jc, viper.GetString("JIRA_BACKEND"))
+	if err != nil {
+		return nil, err
+	}
+
+	// progress goes to stderr so the results can be piped
+	fmt.Fprintf(os.Stderr, "Fetching Sprints from project %s...\n", jiraProject)
+
+	sprintList, err := sprintLister.Get(ctx, jiraProject, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting Sprint list")
+	}
+
+	var selected []sprint
+	if selection.empty() {
+		selected = sprintsOfYear(sprintList.Sprints, sprintNames, year)
+	} else {
+		selected, err = selectSprints(ctx, sprintLister, sprintList.Sprints, sprintNames)
+		if err != nil {
+			return nil, errors.Wrap(err, "error selecting Sprints")
+		}
+	}
+
+	if len(selected) == 0 {
+		return nil, errors.New("no closed Sprint selected")
+	}
+
+	refs := make([]helper.SprintRef, len(selected))
+	for i, s := range selected {
+		refs[i] = helper.SprintRef{ID: s.id, Name: s.name}
+	}
+
+	fmt.Fprintf(os.Stderr, "Fetching %d Sprint reports...\n", len(refs))
+
+	return helper.FetchReports(ctx, reportGetter, jiraProject, refs, concurrencyLimit())
+}
+
+// newSink creates the sinks requested for the report commands, nil when none
+func newSink(ctx context.Context) (sink.Sink, error) {
+
+	var sinks sink.Multi
+
+	for _, t := range reportSinks {
+		switch strings.ToLower(t) {
+		case sink.TypeSheet:
+			spreadSheetsHelper, err := newSpreadSheetHelper(ctx)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, sink.Sheet{
+				Helper:        spreadSheetsHelper,
+				SpreadSheetID: viper.GetString("GOOGLE_SPREADSHEET"),
+				Range: func(tableName string) string {
+					return viper.GetString("GOOGLE_SPREADSHEET_" + strings.ToUpper(tableName) + "_WR")
+				},
+			})
+		case sink.TypeCSV:
+			sinks = append(sinks, sink.CSV{Dir: reportOutputDir})
+		case sink.TypeJSON:
+			sinks = append(sinks, sink.JSON{Dir: reportOutputDir})
+		}
+	}
+
+	if len(sinks) == 0 {
+		return nil, nil
+	}
+
+	return sinks, nil
+}
+
+// writeToSinks writes a table to the requested sinks, if any
+func writeToSinks(ctx context.Context, table sink.Table) error {
+
+	s, err := newSink(ctx)
+	if err != nil || s == nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Writing %d rows of %s to %s...\n", len(table.Rows), table.Name, strings.Join(reportSinks, ", "))
+
+	return s.Write(ctx, table)
+}
+
+// newPointsFormat reads how story points are rounded for display
+func newPointsFormat() (helper.PointsFormat, error) {
+
+	decimals := helper.DefaultPointsDecimals
+	if viper.IsSet("POINTS_DECIMALS") {
+		decimals = viper.GetInt("POINTS_DECIMALS")
+	}
+
+	points, err := helper.NewPointsFormat(decimals, viper.GetString("POINTS_ROUNDING"))
+	if err != nil {
+		return points, errors.Wrap(err, "error reading POINTS_DECIMALS or POINTS_ROUNDING")
+	}
+
+	return points, nil
+}
+
+// newMeasure reads how issues weigh in the metrics of the board: --metrics,
+// the mode of the board in METRICS_MODE_BOARDS or METRICS_MODE, in that order
+func newMeasure() (helper.Measure, error) {
+
+	mode := metricsMode
+	if mode == "" {
+		mode = viper.GetStringMapString("METRICS_MODE_BOARDS")[jiraProject]
+	}
+	if mode == "" {
+		mode = viper.GetString("METRICS_MODE")
+	}
+
+	measure, err := helper.NewMeasure(mode)
+	if err != nil {
+		return measure, errors.Wrap(err, "error reading the metrics mode")
+	}
+
+	return measure, nil
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/jvalecillos/jira-metrics/pkg/googlesheets"
+	"github.com/jvalecillos/jira-metrics/pkg/helper"
+	"github.com/jvalecillos/jira-metrics/pkg/sink"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var minCarryOvers int
+
+// reportCarryOversCmd represents the report carryovers command
+var reportCarryOversCmd = &cobra.Command{
+	Use:   "carryovers",
+	Short: "Lists the issues carried over across consecutive Sprints",
+	Long: `Follows the issues across the reports of the selected Sprints and lists
+the ones carried over at least --min times, with the number of consecutive
+Sprints they were carried over and the points spilled in each Sprint, or
+the Sprints they spilled from when counting issues. The table is written to
+the sinks as "carryovers", the sheet sink writes it to
+GOOGLE_SPREADSHEET_CARRYOVERS_WR, e.g. a "Chronic carry-overs" sheet.
+
+Example: jira-metrics report carryovers --project 123 --last 8 [--min 3] [--sink sheet]
+         jira-metrics report carryovers --project 123 --year 2021 --format json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		ctx := cmd.Context()
+
+		jc, err := newJiraClient(ctx)
+		if err != nil {
+			return err
+		}
+
+		sprintNames, err := newSprintNameHelper()
+		if err != nil {
+			return err
+		}
+
+		reports, err := fetchSelectedReports(ctx, jc, sprintNames)
+		if err != nil {
+			return err
+		}
+
+		points, err := newPointsFormat()
+		if err != nil {
+			return err
+		}
+
+		measure, err := newMeasure()
+		if err != nil {
+			return err
+		}
+
+		carryOvers := helper.NewCarryOverHelper(sprintNames, jc.BrowseURL(), measure)
+		chains := carryOvers.Track(reports, minCarryOvers)
+
+		if err := writeToSinks(ctx, sink.Table{
+			Name:   "carryovers",
+			Header: googlesheets.Headers(googlesheets.CarryOverRow{}),
+			Rows:   carryOvers.Rows(chains).Convert(),
+		}); err != nil {
+			return errors.Wrap(err, "error writing chronic carry-overs")
+		}
+
+		if reportFormat == formatJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(chains)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tCARRY-OVERS\tSTREAK\tSPRINTS\tSPILLED\tTOTAL\tCOMPLETED IN\tSUMMARY")
+		for _, c := range chains {
+			completedIn := c.CompletedIn
+			if completedIn == "" {
+				completedIn = "-"
+			}
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\t%s\t%s\t%s\n",
+				c.Key, c.CarryOvers, c.LongestStreak, c.SprintsTouched,
+				c.SpilledSummary(points), points.Format(c.TotalSpilled), completedIn, c.Summary)
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	reportCmd.AddCommand(reportCarryOversCmd)
+
+	// flags and configuration settings.
+	addReportFlags(reportCarryOversCmd)
+	reportCarryOversCmd.Flags().IntVar(&minCarryOvers, "min", 2, "Minimum number of carry-overs of the listed issues")
+}
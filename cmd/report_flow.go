@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jvalecillos/jira-metrics/pkg/googlesheets"
+	"github.com/jvalecillos/jira-metrics/pkg/helper"
+	"github.com/jvalecillos/jira-metrics/pkg/sink"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// reportFlowCmd represents the report flow command
+var reportFlowCmd = &cobra.Command{
+	Use:   "flow",
+	Short: "Shows the lead time, cycle time and time in status of the issues",
+	Long: `Fetches the changelog of every issue of the selected Sprints and replays
+its status changes to compute its lead time (created to done), cycle time
+(first in progress to done) and the days spent in each status. Statuses are
+classified by their JIRA status category unless FLOW_STATUS_CATEGORIES maps
+their name to todo, in-progress or done. The table is written to the sinks as
+"flow", the sheet sink writes it to GOOGLE_SPREADSHEET_FLOW_WR.
+
+Example: jira-metrics report flow --project 123 --last 4 [--sink sheet]
+         jira-metrics report flow --project 123 --sprint-id 1234 --format json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		ctx := cmd.Context()
+
+		jc, err := newJiraClient(ctx)
+		if err != nil {
+			return err
+		}
+
+		sprintNames, err := newSprintNameHelper()
+		if err != nil {
+			return err
+		}
+
+		reports, err := fetchSelectedReports(ctx, jc, sprintNames)
+		if err != nil {
+			return err
+		}
+
+		changelogs, err := jc.IssueChangelogs()
+		if err != nil {
+			return err
+		}
+
+		statuses, err := changelogs.Statuses(ctx)
+		if err != nil {
+			return errors.Wrap(err, "error getting issue statuses")
+		}
+
+		categories, err := helper.NewFlowCategories(statuses, viper.GetStringMapString("FLOW_STATUS_CATEGORIES"))
+		if err != nil {
+			return errors.Wrap(err, "error reading FLOW_STATUS_CATEGORIES")
+		}
+
+		fmt.Fprintf(os.Stderr, "Fetching issue changelogs...\n")
+
+		flowHelper := helper.NewFlowHelper(changelogs, categories, sprintNames, jc.BrowseURL(), concurrencyLimit())
+		flows, err := flowHelper.ProcessReports(ctx, reports, time.Now())
+		if err != nil {
+			return err
+		}
+
+		if err := writeToSinks(ctx, sink.Table{
+			Name:   "flow",
+			Header: googlesheets.Headers(googlesheets.FlowRow{}),
+			Rows:   flowHelper.FlowRows(flows).Convert(),
+		}); err != nil {
+			return errors.Wrap(err, "error writing flow metrics")
+		}
+
+		lead, cycle := helper.FlowSummary(flows)
+
+		if reportFormat == formatJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(struct {
+				Issues    []helper.FlowIssue `json:"issues"`
+				LeadTime  helper.FlowStats   `json:"leadTime"`
+				CycleTime helper.FlowStats   `json:"cycleTime"`
+			}{flows, lead, cycle})
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tSTATUS\tLEAD TIME\tCYCLE TIME\tTIME IN STATUS")
+		for _, f := range flows {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+				f.Key, f.Status, helper.FormatDays(f.LeadTime, "-"), helper.FormatDays(f.CycleTime, "-"), f.TimeInStatusSummary())
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		fmt.Println()
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "DAYS\tISSUES\tAVG\tMEDIAN\tP85")
+		for _, s := range []struct {
+			name  string
+			stats helper.FlowStats
+		}{{"Lead time", lead}, {"Cycle time", cycle}} {
+			fmt.Fprintf(w, "%s\t%d\t%g\t%g\t%g\n",
+				s.name, s.stats.Issues, s.stats.Average, s.stats.Median, s.stats.Percentile85)
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	reportCmd.AddCommand(reportFlowCmd)
+
+	// flags and configuration settings.
+	addReportFlags(reportFlowCmd)
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/jvalecillos/jira-metrics/pkg/googlesheets"
+	"github.com/jvalecillos/jira-metrics/pkg/helper"
+	"github.com/jvalecillos/jira-metrics/pkg/sink"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var velocityWindow int
+
+// reportVelocityCmd represents the report velocity command
+var reportVelocityCmd = &cobra.Command{
+	Use:   "velocity",
+	Short: "Shows the velocity and predictability trend of the selected Sprints",
+	Long: `Computes the completed points of the selected Sprints along with their
+rolling average, median, standard deviation and coefficient of variation over
+the last --window Sprints, and a predictability score comparing completed and
+committed points. The series is written to the sinks as "velocity", the sheet
+sink writes it to GOOGLE_SPREADSHEET_VELOCITY_WR.
+
+Example: jira-metrics report velocity --project 123 --last 12 [--window 3] [--sink csv]
+         jira-metrics report velocity --project 123 --year 2021 --format json`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if velocityWindow < 1 {
+			return errors.New("--window must be a positive number")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		ctx := cmd.Context()
+
+		jc, err := newJiraClient(ctx)
+		if err != nil {
+			return err
+		}
+
+		sprintNames, err := newSprintNameHelper()
+		if err != nil {
+			return err
+		}
+
+		reports, err := fetchSelectedReports(ctx, jc, sprintNames)
+		if err != nil {
+			return err
+		}
+
+		pf, err := newPointsFormat()
+		if err != nil {
+			return err
+		}
+
+		measure, err := newMeasure()
+		if err != nil {
+			return err
+		}
+
+		points, overall := helper.Velocity(reports, sprintNames, measure, velocityWindow)
+
+		if err := writeToSinks(ctx, sink.Table{
+			Name:   "velocity",
+			Header: googlesheets.Headers(googlesheets.VelocityRow{}),
+			Rows:   helper.VelocityRows(points).Convert(),
+		}); err != nil {
+			return errors.Wrap(err, "error writing velocity")
+		}
+
+		if reportFormat == formatJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(struct {
+				Sprints []helper.VelocityPoint `json:"sprints"`
+				Overall helper.VelocityStats   `json:"overall"`
+			}{points, overall})
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SPRINT\tCOMMITTED\tCOMPLETED\tSAY/DO\tAVG\tMEDIAN\tSTDDEV\tCV\tPREDICTABILITY")
+		for _, p := range points {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				p.Sprint, pf.Format(p.Committed), pf.Format(p.Completed), formatPercent(p.SayDo),
+				pf.Format(p.Rolling.Average), pf.Format(p.Rolling.Median), pf.Format(p.Rolling.StdDev),
+				formatPercent(p.Rolling.CV), formatPercent(p.Rolling.Predictability))
+		}
+		fmt.Fprintf(w, "ALL (%d)\t\t\t\t%s\t%s\t%s\t%s\t%s\n",
+			overall.Sprints, pf.Format(overall.Average), pf.Format(overall.Median), pf.Format(overall.StdDev),
+			formatPercent(overall.CV), formatPercent(overall.Predictability))
+		return w.Flush()
+	},
+}
+
+func init() {
+	reportCmd.AddCommand(reportVelocityCmd)
+
+	// flags and configuration settings.
+	addReportFlags(reportVelocityCmd)
+	reportVelocityCmd.Flags().IntVarP(&velocityWindow, "window", "w", 3, "Number of Sprints of the rolling window")
+}
+
+// formatPercent prints an optional ratio as a percentage
+func formatPercent(value *float64) string {
+	if value == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", *value*100)
+}
@@ -1,14 +1,25 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var cfgFile string
+var timeout time.Duration
+
+// cancelRun cancels the context of the running command, timedOut records
+// whether it was cancelled by --timeout rather than by a signal
+var cancelRun context.CancelFunc = func() {}
+var timedOut int32
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -19,12 +30,43 @@ metrics and populates them in a Googlesheet for comparison.`,
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	// Run: func(cmd *cobra.Command, args []string) { },
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if timeout > 0 {
+			time.AfterFunc(timeout, func() {
+				atomic.StoreInt32(&timedOut, 1)
+				cancelRun()
+			})
+		}
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// Commands run with a context cancelled on SIGINT, SIGTERM or once --timeout
+// elapses.
 func Execute() {
-	cobra.CheckErr(rootCmd.Execute())
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	ctx, cancelRun = context.WithCancel(ctx)
+
+	err := rootCmd.ExecuteContext(ctx)
+	if err != nil && ctx.Err() != nil {
+		if atomic.LoadInt32(&timedOut) == 1 {
+			fmt.Fprintf(os.Stderr, "Timed out after %s\n", timeout)
+		} else {
+			fmt.Fprintln(os.Stderr, "Interrupted")
+		}
+	}
+
+	cancelRun()
+	stop()
+
+	if flushErr := flushIssueCache(); flushErr != nil {
+		fmt.Fprintln(os.Stderr, "Warning: unable to save the issue cache:", flushErr)
+	}
+	if hint := errorHint(err); hint != "" {
+		fmt.Fprintln(os.Stderr, "Hint:", hint)
+	}
+	cobra.CheckErr(err)
 }
 
 func init() {
@@ -35,6 +77,7 @@ func init() {
 	// will be global for your application.
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.jira-metrics.yaml)")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "Stop the command after this duration, e.g. 10m (0 means no timeout)")
 
 	// // Cobra also supports local flags, which will only run
 	// // when this action is called directly.
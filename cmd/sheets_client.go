@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/jvalecillos/jira-metrics/pkg/googlesheets"
+	"github.com/jvalecillos/jira-metrics/pkg/helper"
+	"github.com/pkg/errors"
+)
+
+// newSpreadSheetHelper creates a Google Sheets client authorized to edit the
+// spreadsheets of the user
+func newSpreadSheetHelper(ctx context.Context) (helper.SpreadSheetHelper, error) {
+
+	googleSheetsSrv, err := googlesheets.NewService(ctx,
+		"credentials.json",
+		// scope for reading
+		// "https://www.googleapis.com/auth/spreadsheets.readonly",
+		// scope to edit only an specific sheet
+		// "https://www.googleapis.com/auth/drive.file",
+		// scope for writing all sheets
+		"https://www.googleapis.com/auth/spreadsheets",
+	)
+
+	if err != nil {
+		return helper.SpreadSheetHelper{}, errors.Wrap(err, "error initializing Google Sheets service")
+	}
+
+	return helper.NewSpreadSheetHelper(googleSheetsSrv), nil
+}
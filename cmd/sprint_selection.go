@@ -0,0 +1,370 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jvalecillos/jira-metrics/pkg/helper"
+	"github.com/jvalecillos/jira-metrics/pkg/jira"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	sprintStateClosed = "CLOSED"
+	// dateLayout is the format of the --from and --to flags
+	dateLayout = "2006-01-02"
+)
+
+// sprintSelection holds the Sprints explicitly requested from the command line
+type sprintSelection struct {
+	ids    []string
+	names  []string
+	latest bool
+	from   string
+	to     string
+	last   int
+}
+
+// empty is true when no Sprint was explicitly requested
+func (s sprintSelection) empty() bool {
+	return len(s.ids) == 0 && len(s.names) == 0 && !s.latest && !s.byDate()
+}
+
+// byDate is true when Sprints are selected by their dates
+func (s sprintSelection) byDate() bool {
+	return s.from != "" || s.to != "" || s.last > 0
+}
+
+// validate checks that the selection flags are consistent
+func (s sprintSelection) validate() error {
+	if s.byDate() && (len(s.ids) > 0 || len(s.names) > 0 || s.latest) {
+		return errors.New("--from, --to and --last can't be combined with --sprint-id, --sprint-name or --latest")
+	}
+	if s.last < 0 {
+		return errors.New("--last must be a positive number")
+	}
+	if _, _, err := s.dateRange(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// dateRange parses the --from and --to flags, both ends are optional and
+// --to includes the whole given day
+func (s sprintSelection) dateRange() (from, to *time.Time, err error) {
+	if s.from != "" {
+		f, err := time.ParseInLocation(dateLayout, s.from, time.Local)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "invalid --from date, expected YYYY-MM-DD")
+		}
+		from = &f
+	}
+	if s.to != "" {
+		t, err := time.ParseInLocation(dateLayout, s.to, time.Local)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "invalid --to date, expected YYYY-MM-DD")
+		}
+		t = t.AddDate(0, 0, 1)
+		to = &t
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, nil, errors.New("--from must be before --to")
+	}
+	return from, to, nil
+}
+
+// addSprintSelectionFlags adds the flags selecting the Sprints of a board
+// to a command
+func addSprintSelectionFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&jiraProject, "project", "p", "", "Project ID from JIRA (required)")
+	cmd.MarkFlagRequired("project")
+	cmd.Flags().StringVarP(&year, "year", "y", "2021", "Year for filtering Sprints")
+	cmd.Flags().StringArrayVar(&selection.ids, "sprint-id", nil, "Select the Sprint with the given ID without prompting (repeatable)")
+	cmd.Flags().StringArrayVar(&selection.names, "sprint-name", nil, "Select the Sprint with the given name without prompting (repeatable)")
+	cmd.Flags().BoolVar(&selection.latest, "latest", false, "Select the most recently completed Sprint without prompting")
+	cmd.Flags().StringVar(&selection.from, "from", "", "Select Sprints completed on or after this date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&selection.to, "to", "", "Select Sprints completed on or before this date (YYYY-MM-DD)")
+	cmd.Flags().IntVar(&selection.last, "last", 0, "Select the last N closed Sprints (within --from/--to when given)")
+}
+
+// newSprintNameHelper reads the Sprint name configuration
+func newSprintNameHelper() (helper.SprintNameHelper, error) {
+
+	sprintNames, err := helper.NewSprintNameHelper(
+		viper.GetString("SPRINT_NAME_PATTERN"),
+		viper.GetString("SPRINT_LABEL_TEMPLATE"),
+		viper.GetStringSlice("SPRINT_INCLUDE"),
+		viper.GetStringSlice("SPRINT_EXCLUDE"),
+	)
+
+	if err != nil {
+		return sprintNames, errors.Wrap(err, "error reading Sprint name configuration")
+	}
+
+	return sprintNames, nil
+}
+
+// selectSprints resolves the Sprints explicitly requested or completed in the
+// requested date range, the Sprint dates are listed with the given lister
+func selectSprints(
+	ctx context.Context,
+	lister jira.SprintLister,
+	sprints []jira.BasicSprint,
+	sprintNames helper.SprintNameHelper,
+) ([]sprint, error) {
+
+	if selection.byDate() {
+		return selection.resolveByDate(ctx, lister, sprints, sprintNames.Excluded)
+	}
+
+	var closed []jira.AgileSprint
+	if selection.latest {
+		var err error
+		closed, err = lister.List(ctx, jiraProject, jira.SprintStateClosed)
+		if err != nil {
+			return nil, errors.Wrap(err, "error getting Sprint dates")
+		}
+	}
+
+	return selection.resolve(sprints, closed)
+}
+
+// sprintsOfYear returns the closed Sprints whose name matches the Sprint name
+// pattern for the given year, in the order of the list
+func sprintsOfYear(sprints []jira.BasicSprint, sprintNames helper.SprintNameHelper, year string) []sprint {
+
+	var result []sprint
+
+	for _, s := range sprints {
+		// filtering non-closed Sprint
+		if s.State != sprintStateClosed {
+			continue
+		}
+		// filtering relevant Sprints by name pattern
+		if !sprintNames.Match(s.Name, year) {
+			continue
+		}
+		result = append(result, sprint{id: strconv.Itoa(s.ID), name: s.Name})
+	}
+
+	return result
+}
+
+// resolveByDate selects the closed Sprints completed within the date range,
+// limited to the last N ones when requested. Sprint dates are taken from the
+// board Sprints listed with dates since the Sprint list doesn't include them.
+// The result is ordered by completion date.
+func (s sprintSelection) resolveByDate(
+	ctx context.Context,
+	lister jira.SprintLister,
+	sprints []jira.BasicSprint,
+	excluded func(name string) bool,
+) ([]sprint, error) {
+
+	closedIDs := make(map[int]bool)
+	for _, bs := range sprints {
+		if bs.State == sprintStateClosed && !excluded(bs.Name) {
+			closedIDs[bs.ID] = true
+		}
+	}
+
+	boardSprints, err := lister.List(ctx, jiraProject, jira.SprintStateClosed)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting Sprint dates")
+	}
+
+	var closed []jira.AgileSprint
+	for _, as := range boardSprints {
+		if closedIDs[as.ID] {
+			closed = append(closed, as)
+		}
+	}
+
+	return s.selectByDate(closed)
+}
+
+// selectByDate selects the given closed Sprints completed within the date
+// range, limited to the last N ones when requested, ordered by completion date
+func (s sprintSelection) selectByDate(sprints []jira.AgileSprint) ([]sprint, error) {
+
+	from, to, err := s.dateRange()
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []jira.AgileSprint
+
+	for _, as := range sprints {
+		closed := as.ClosingDate()
+		if closed == nil {
+			continue
+		}
+		if from != nil && closed.Before(*from) {
+			continue
+		}
+		if to != nil && !closed.Before(*to) {
+			continue
+		}
+
+		candidates = append(candidates, as)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].ClosingDate().Before(*candidates[j].ClosingDate())
+	})
+
+	if s.last > 0 && len(candidates) > s.last {
+		candidates = candidates[len(candidates)-s.last:]
+	}
+
+	if len(candidates) == 0 {
+		return nil, errors.New("no closed Sprint found in the given date range")
+	}
+
+	result := make([]sprint, len(candidates))
+	for i, as := range candidates {
+		result[i] = sprint{id: strconv.Itoa(as.ID), name: as.Name}
+	}
+
+	return result, nil
+}
+
+// resolve looks up the requested Sprints in the given list, the latest one
+// is found by the dates of the closed board Sprints. The result keeps the
+// order of the list and contains each Sprint only once.
+func (s sprintSelection) resolve(sprints []jira.BasicSprint, closed []jira.AgileSprint) ([]sprint, error) {
+
+	selected := make(map[int]bool)
+
+	for _, id := range s.ids {
+		found, err := findSprintByID(sprints, id)
+		if err != nil {
+			return nil, err
+		}
+		selected[found.ID] = true
+	}
+
+	for _, name := range s.names {
+		found, err := findSprintByName(sprints, name)
+		if err != nil {
+			return nil, err
+		}
+		selected[found.ID] = true
+	}
+
+	if s.latest {
+		found, err := findLatestClosedSprint(sprints, closed)
+		if err != nil {
+			return nil, err
+		}
+		selected[found.ID] = true
+	}
+
+	var result []sprint
+	for _, bs := range sprints {
+		if selected[bs.ID] {
+			result = append(result, sprint{id: strconv.Itoa(bs.ID), name: bs.Name})
+		}
+	}
+
+	return result, nil
+}
+
+// findSprintByID returns the closed Sprint with the given ID
+func findSprintByID(sprints []jira.BasicSprint, id string) (jira.BasicSprint, error) {
+	for _, bs := range sprints {
+		if strconv.Itoa(bs.ID) != id {
+			continue
+		}
+		if bs.State != sprintStateClosed {
+			return bs, errors.Errorf("Sprint %s (%s) is %s, only closed Sprints can be synced", id, bs.Name, bs.State)
+		}
+		return bs, nil
+	}
+	return jira.BasicSprint{}, errors.Errorf("no Sprint found with ID %s", id)
+}
+
+// findSprintByName returns the closed Sprint matching the given name. An exact
+// match is preferred, otherwise a single case-insensitive partial match is accepted.
+func findSprintByName(sprints []jira.BasicSprint, name string) (jira.BasicSprint, error) {
+
+	var exact, partial []jira.BasicSprint
+
+	for _, bs := range sprints {
+		if bs.State != sprintStateClosed {
+			continue
+		}
+		if bs.Name == name {
+			exact = append(exact, bs)
+		} else if strings.Contains(strings.ToLower(bs.Name), strings.ToLower(name)) {
+			partial = append(partial, bs)
+		}
+	}
+
+	candidates := exact
+	if len(candidates) == 0 {
+		candidates = partial
+	}
+
+	switch len(candidates) {
+	case 0:
+		return jira.BasicSprint{}, errors.Errorf("no closed Sprint found matching name %q", name)
+	case 1:
+		return candidates[0], nil
+	default:
+		return jira.BasicSprint{}, errors.Errorf(
+			"Sprint name %q is ambiguous, it matches: %s (use --sprint-id instead)",
+			name, describeSprints(candidates),
+		)
+	}
+}
+
+// findLatestClosedSprint returns the most recently completed closed Sprint
+// using the dates of the closed board Sprints. The sequence in the board only
+// breaks ties since the Agile API backend numbers Sprints in the list order.
+func findLatestClosedSprint(sprints []jira.BasicSprint, closed []jira.AgileSprint) (jira.BasicSprint, error) {
+
+	closingDates := make(map[int]time.Time, len(closed))
+	for _, as := range closed {
+		if d := as.ClosingDate(); d != nil {
+			closingDates[as.ID] = *d
+		}
+	}
+
+	var latest *jira.BasicSprint
+
+	for i, bs := range sprints {
+		if bs.State != sprintStateClosed {
+			continue
+		}
+		if latest == nil {
+			latest = &sprints[i]
+			continue
+		}
+		d, latestDate := closingDates[bs.ID], closingDates[latest.ID]
+		if d.After(latestDate) || d.Equal(latestDate) && bs.Sequence > latest.Sequence {
+			latest = &sprints[i]
+		}
+	}
+
+	if latest == nil {
+		return jira.BasicSprint{}, errors.New("no closed Sprint found")
+	}
+
+	return *latest, nil
+}
+
+// describeSprints lists Sprints as "name (ID)" for error messages
+func describeSprints(sprints []jira.BasicSprint) string {
+	descriptions := make([]string, len(sprints))
+	for i, bs := range sprints {
+		descriptions[i] = fmt.Sprintf("%s (%d)", bs.Name, bs.ID)
+	}
+	return strings.Join(descriptions, ", ")
+}
@@ -0,0 +1,293 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jvalecillos/jira-metrics/pkg/jira"
+)
+
+// testSprints is a board Sprint list as returned by JIRA
+var testSprints = []jira.BasicSprint{
+	{ID: 10, Sequence: 10, Name: "STR Sprint 2021-W35-37", State: "CLOSED"},
+	{ID: 11, Sequence: 11, Name: "STR Sprint 2021-W38-40", State: "CLOSED"},
+	{ID: 12, Sequence: 12, Name: "STR Sprint 2021-W41-43", State: "CLOSED"},
+	{ID: 13, Sequence: 13, Name: "STR Sprint 2021-W41-43 (hotfix)", State: "CLOSED"},
+	{ID: 14, Sequence: 14, Name: "STR Sprint 2021-W44-46", State: "ACTIVE"},
+}
+
+// testClosedSprints are the closed board Sprints of testSprints with dates
+var testClosedSprints = []jira.AgileSprint{
+	{ID: 10, Name: "STR Sprint 2021-W35-37", CompleteDate: day(time.September, 17, 17)},
+	{ID: 11, Name: "STR Sprint 2021-W38-40", CompleteDate: day(time.October, 8, 17)},
+	{ID: 12, Name: "STR Sprint 2021-W41-43", CompleteDate: day(time.October, 29, 17)},
+	{ID: 13, Name: "STR Sprint 2021-W41-43 (hotfix)", CompleteDate: day(time.November, 2, 9)},
+}
+
+func TestSprintSelectionResolve(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		selection sprintSelection
+		want      []sprint
+		wantErr   bool
+	}{
+		{
+			name:      "by ID",
+			selection: sprintSelection{ids: []string{"11"}},
+			want:      []sprint{{id: "11", name: "STR Sprint 2021-W38-40"}},
+		},
+		{
+			name:      "unknown ID",
+			selection: sprintSelection{ids: []string{"99"}},
+			wantErr:   true,
+		},
+		{
+			name:      "ID of a Sprint not closed",
+			selection: sprintSelection{ids: []string{"14"}},
+			wantErr:   true,
+		},
+		{
+			name:      "exact name before partial matches",
+			selection: sprintSelection{names: []string{"STR Sprint 2021-W41-43"}},
+			want:      []sprint{{id: "12", name: "STR Sprint 2021-W41-43"}},
+		},
+		{
+			name:      "single case insensitive partial match",
+			selection: sprintSelection{names: []string{"w38"}},
+			want:      []sprint{{id: "11", name: "STR Sprint 2021-W38-40"}},
+		},
+		{
+			name:      "ambiguous partial match",
+			selection: sprintSelection{names: []string{"W41-43"}},
+			wantErr:   true,
+		},
+		{
+			name:      "names only match closed Sprints",
+			selection: sprintSelection{names: []string{"W44-46"}},
+			wantErr:   true,
+		},
+		{
+			name:      "latest closed Sprint",
+			selection: sprintSelection{latest: true},
+			want:      []sprint{{id: "13", name: "STR Sprint 2021-W41-43 (hotfix)"}},
+		},
+		{
+			name: "list order without duplicates",
+			selection: sprintSelection{
+				ids:   []string{"12", "10"},
+				names: []string{"W35-37", "hotfix"},
+			},
+			want: []sprint{
+				{id: "10", name: "STR Sprint 2021-W35-37"},
+				{id: "12", name: "STR Sprint 2021-W41-43"},
+				{id: "13", name: "STR Sprint 2021-W41-43 (hotfix)"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.selection.resolve(testSprints, testClosedSprints)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindSprintByNameWithDuplicateNames(t *testing.T) {
+
+	sprints := append([]jira.BasicSprint{
+		{ID: 9, Sequence: 9, Name: "STR Sprint 2021-W41-43", State: "CLOSED"},
+	}, testSprints...)
+
+	if _, err := findSprintByName(sprints, "STR Sprint 2021-W41-43"); err == nil {
+		t.Error("findSprintByName() with two exact matches didn't fail")
+	}
+}
+
+func TestFindLatestClosedSprint(t *testing.T) {
+
+	if _, err := findLatestClosedSprint(testSprints[4:], testClosedSprints); err == nil {
+		t.Error("findLatestClosedSprint() without closed Sprints didn't fail")
+	}
+
+	tests := []struct {
+		name    string
+		sprints []jira.BasicSprint
+		closed  []jira.AgileSprint
+		want    int
+	}{
+		{
+			// Greenhopper sequences follow the creation of the Sprints, the
+			// hotfix Sprint was created last but closed before W41-43
+			name: "greenhopper sequence",
+			sprints: []jira.BasicSprint{
+				{ID: 12, Sequence: 12, Name: "W41-43", State: "CLOSED"},
+				{ID: 13, Sequence: 13, Name: "W41-43 (hotfix)", State: "CLOSED"},
+				{ID: 10, Sequence: 10, Name: "W35-37", State: "CLOSED"},
+				{ID: 14, Sequence: 14, Name: "W44-46", State: "ACTIVE"},
+			},
+			closed: []jira.AgileSprint{
+				{ID: 10, CompleteDate: day(time.September, 17, 17)},
+				{ID: 12, CompleteDate: day(time.October, 29, 17)},
+				{ID: 13, CompleteDate: day(time.October, 20, 12)},
+			},
+			want: 12,
+		},
+		{
+			// the Agile API backend numbers the Sprints in the list order
+			name: "agile list order",
+			sprints: []jira.BasicSprint{
+				{ID: 11, Sequence: 1, Name: "W38-40", State: "CLOSED"},
+				{ID: 10, Sequence: 2, Name: "W35-37", State: "CLOSED"},
+				{ID: 14, Sequence: 3, Name: "W44-46", State: "ACTIVE"},
+			},
+			closed: []jira.AgileSprint{
+				{ID: 11, EndDate: day(time.October, 8, 17)},
+				{ID: 10, CompleteDate: day(time.September, 17, 17)},
+			},
+			want: 11,
+		},
+		{
+			name: "same completion date",
+			sprints: []jira.BasicSprint{
+				{ID: 13, Sequence: 13, Name: "W41-43 (hotfix)", State: "CLOSED"},
+				{ID: 12, Sequence: 12, Name: "W41-43", State: "CLOSED"},
+			},
+			closed: []jira.AgileSprint{
+				{ID: 12, CompleteDate: day(time.October, 29, 17)},
+				{ID: 13, CompleteDate: day(time.October, 29, 17)},
+			},
+			want: 13,
+		},
+		{
+			name: "Sprints without dates first",
+			sprints: []jira.BasicSprint{
+				{ID: 9, Sequence: 9, Name: "never started", State: "CLOSED"},
+				{ID: 10, Sequence: 2, Name: "W35-37", State: "CLOSED"},
+			},
+			closed: []jira.AgileSprint{
+				{ID: 9},
+				{ID: 10, CompleteDate: day(time.September, 17, 17)},
+			},
+			want: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findLatestClosedSprint(tt.sprints, tt.closed)
+			if err != nil {
+				t.Fatalf("findLatestClosedSprint: %v", err)
+			}
+			if got.ID != tt.want {
+				t.Errorf("findLatestClosedSprint() = %d, want %d", got.ID, tt.want)
+			}
+		})
+	}
+}
+
+// day returns the given local time of a day of 2021
+func day(month time.Month, d, hour int) *time.Time {
+	t := time.Date(2021, month, d, hour, 0, 0, 0, time.Local)
+	return &t
+}
+
+func TestSprintSelectionDateRange(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		selection sprintSelection
+		from, to  *time.Time
+		wantErr   bool
+	}{
+		{name: "open range", selection: sprintSelection{last: 2}},
+		{
+			name:      "to includes the whole day",
+			selection: sprintSelection{from: "2021-10-01", to: "2021-10-31"},
+			from:      day(time.October, 1, 0),
+			to:        day(time.November, 1, 0),
+		},
+		{
+			name:      "single day",
+			selection: sprintSelection{from: "2021-10-01", to: "2021-10-01"},
+			from:      day(time.October, 1, 0),
+			to:        day(time.October, 2, 0),
+		},
+		{name: "from after to", selection: sprintSelection{from: "2021-10-02", to: "2021-10-01"}, wantErr: true},
+		{name: "invalid from", selection: sprintSelection{from: "01/10/2021"}, wantErr: true},
+		{name: "invalid to", selection: sprintSelection{to: "2021-10"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, err := tt.selection.dateRange()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("dateRange() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(from, tt.from) || !reflect.DeepEqual(to, tt.to) {
+				t.Errorf("dateRange() = %v, %v, want %v, %v", from, to, tt.from, tt.to)
+			}
+		})
+	}
+}
+
+func TestSprintSelectionSelectByDate(t *testing.T) {
+
+	// closed Sprints in the order of the list, not of their completion
+	sprints := []jira.AgileSprint{
+		{ID: 12, Name: "W41-43", CompleteDate: day(time.October, 29, 17)},
+		{ID: 10, Name: "W35-37", CompleteDate: day(time.September, 17, 17)},
+		{ID: 13, Name: "W44-46", CompleteDate: day(time.November, 19, 9)},
+		{ID: 11, Name: "W38-40", EndDate: day(time.October, 8, 17)},
+		{ID: 9, Name: "never started"},
+	}
+
+	tests := []struct {
+		name      string
+		selection sprintSelection
+		want      []string
+		wantErr   bool
+	}{
+		{name: "last N by completion date", selection: sprintSelection{last: 2}, want: []string{"12", "13"}},
+		{name: "more than available", selection: sprintSelection{last: 10}, want: []string{"10", "11", "12", "13"}},
+		{
+			name:      "to includes Sprints closed during the day",
+			selection: sprintSelection{from: "2021-10-08", to: "2021-10-29"},
+			want:      []string{"11", "12"},
+		},
+		{
+			name:      "from excludes Sprints closed the day before",
+			selection: sprintSelection{from: "2021-09-18"},
+			want:      []string{"11", "12", "13"},
+		},
+		{
+			name:      "last within the range",
+			selection: sprintSelection{to: "2021-10-31", last: 2},
+			want:      []string{"11", "12"},
+		},
+		{name: "empty range", selection: sprintSelection{from: "2021-12-01"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.selection.selectByDate(sprints)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectByDate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var ids []string
+			for _, s := range got {
+				ids = append(ids, s.id)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("selectByDate() = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jvalecillos/jira-metrics/pkg/jira"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+var sprintsBoard string
+var sprintsStates []string
+var sprintsFormat string
+
+// sprintsCmd represents the sprints command
+var sprintsCmd = &cobra.Command{
+	Use:   "sprints",
+	Short: "Inspects the Sprints of a JIRA board",
+}
+
+// sprintsListCmd represents the sprints list command
+var sprintsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the Sprints of a JIRA board",
+	Long: `Lists the Sprints of a JIRA board in the given states, following all
+the pages of the board history.
+
+Example: jira-metrics sprints list --board 123 --state active,closed --format table`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		for _, state := range sprintsStates {
+			switch strings.ToLower(state) {
+			case jira.SprintStateActive, jira.SprintStateClosed, jira.SprintStateFuture:
+			default:
+				return errors.Errorf("unknown Sprint state %q, expected active, closed or future", state)
+			}
+		}
+		if sprintsFormat != formatTable && sprintsFormat != formatJSON {
+			return errors.Errorf("unknown format %q, expected %s or %s", sprintsFormat, formatTable, formatJSON)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		jc, err := newJiraClient(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		sprintLister, _, err := newJiraBackend(jc, viper.GetString("JIRA_BACKEND"))
+		if err != nil {
+			return err
+		}
+
+		states := make([]string, len(sprintsStates))
+		for i, state := range sprintsStates {
+			states[i] = strings.ToLower(state)
+		}
+
+		sprints, err := sprintLister.List(cmd.Context(), sprintsBoard, states...)
+		if err != nil {
+			return errors.Wrap(err, "error getting Sprint list")
+		}
+
+		if sprintsFormat == formatJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(sprints)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tSTATE\tSTART\tEND\tCOMPLETE\tNAME")
+		for _, s := range sprints {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n",
+				s.ID, s.State, formatDate(s.StartDate), formatDate(s.EndDate), formatDate(s.CompleteDate), s.Name)
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sprintsCmd)
+	sprintsCmd.AddCommand(sprintsListCmd)
+
+	// flags and configuration settings.
+	sprintsListCmd.Flags().StringVarP(&sprintsBoard, "board", "b", "", "Board (rapid view) ID from JIRA (required)")
+	sprintsListCmd.MarkFlagRequired("board")
+	sprintsListCmd.Flags().StringSliceVarP(&sprintsStates, "state", "s", []string{jira.SprintStateActive, jira.SprintStateClosed}, "Sprint states to list: active, closed, future")
+	sprintsListCmd.Flags().StringVarP(&sprintsFormat, "format", "f", formatTable, "Output format: table or json")
+}
+
+// formatDate prints an optional date as YYYY-MM-DD
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(dateLayout)
+}
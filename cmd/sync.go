@@ -3,8 +3,7 @@ package cmd
 import (
 	"context"
 	"fmt"
-	"regexp"
-	"strconv"
+	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/jvalecillos/jira-metrics/pkg/googlesheets"
@@ -18,12 +17,23 @@ import (
 type serviceWrapper struct {
 	context            context.Context
 	jiraClient         *jira.Jira
+	sprintLister       jira.SprintLister
+	reportGetter       jira.ReportGetter
 	spreadSheetsHelper helper.SpreadSheetHelper
+	sprintNames        helper.SprintNameHelper
+	disciplineHelper   helper.DisciplineHelper
+	measure            helper.Measure
 }
 
 var all bool
+var upsert bool
+var prune bool
+var strict bool
+var concurrency int
+var issueConcurrency int
 var year string
 var jiraProject string
+var selection sprintSelection
 var sv *serviceWrapper
 
 type sprint struct {
@@ -38,81 +48,102 @@ var syncCmd = &cobra.Command{
 	Long: `Fetches the Sprint information from JIRA and syncs it with
 the given GoogleSheet.
 
-Example: jira-metrics sync --year 2021 [--all | --sprint-week 41-43]`,
+Example: jira-metrics sync --year 2021 [--all | --sprint-week 41-43] [--upsert [--prune]]
+         jira-metrics sync [--sprint-id 1234 | --sprint-name "Sprint 2021-W41-43" | --latest]
+         jira-metrics sync [--from 2021-01-01] [--to 2021-12-31] [--last 6]`,
 	PreRunE: func(cmd *cobra.Command, args []string) error {
 
-		ctx := context.Background()
+		if all && !selection.empty() {
+			return errors.New("--all can't be combined with --sprint-id, --sprint-name, --latest, --from, --to or --last")
+		}
+
+		if err := selection.validate(); err != nil {
+			return err
+		}
+
+		if prune && !upsert {
+			return errors.New("--prune can only be used together with --upsert")
+		}
+
+		ctx := cmd.Context()
+
+		jc, err := newJiraClient(ctx, jira.WithConcurrency(issueConcurrencyLimit()))
+		if err != nil {
+			return err
+		}
+
+		spreadSheetsHelper, err := newSpreadSheetHelper(ctx)
+		if err != nil {
+			return err
+		}
 
-		// https://support.atlassian.com/atlassian-account/docs/manage-api-tokens-for-your-atlassian-account/
-		jc, err := jira.New(jira.Config{
-			Username:       viper.GetString("JIRA_USERNAME"),
-			Password:       viper.GetString("JIRA_TOKEN"),
-			EndpointPrefix: viper.GetString("JIRA_ENDPOINT_PREFIX"),
-		}, nil)
+		sprintNames, err := newSprintNameHelper()
+		if err != nil {
+			return err
+		}
 
+		disciplineHelper, err := newDisciplineHelper()
 		if err != nil {
-			return errors.Wrap(err, "error creating JIRA client")
+			return err
 		}
 
-		googleSheetsSrv, err := googlesheets.NewService(ctx,
-			"credentials.json",
-			// scope for reading
-			// "https://www.googleapis.com/auth/spreadsheets.readonly",
-			// scope to edit only an specific sheet
-			// "https://www.googleapis.com/auth/drive.file",
-			// scope for writing all sheets
-			"https://www.googleapis.com/auth/spreadsheets",
-		)
+		sprintLister, reportGetter, err := newJiraBackend(jc, viper.GetString("JIRA_BACKEND"))
+		if err != nil {
+			return err
+		}
 
+		measure, err := newMeasure()
 		if err != nil {
-			return errors.Wrap(err, "error initializing Google Sheets service")
+			return err
 		}
 
 		sv = &serviceWrapper{
 			context:            ctx,
 			jiraClient:         jc,
-			spreadSheetsHelper: helper.NewSpreadSheetHelper(googleSheetsSrv),
+			sprintLister:       sprintLister,
+			reportGetter:       reportGetter,
+			spreadSheetsHelper: spreadSheetsHelper,
+			sprintNames:        sprintNames,
+			disciplineHelper:   disciplineHelper,
+			measure:            measure,
 		}
 
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 
-		SprintListSrv, _ := sv.jiraClient.Sprints()
-
 		fmt.Printf("Fetching Sprints from project %s...\n", jiraProject)
 
-		sprintList, err := SprintListSrv.Get(sv.context, jiraProject, false)
+		sprintList, err := sv.sprintLister.Get(sv.context, jiraProject, false)
 		if err != nil {
 			return errors.Wrap(err, "error getting Sprint list")
 		}
 
-		fmt.Printf("Filtering Sprints from year %s...\n", year)
+		// Syncing explicitly requested Sprints or Sprints completed in a date range
+		if !selection.empty() {
+			if selection.byDate() {
+				fmt.Printf("Selecting Sprints by date...\n")
+			}
+			selectedSprints, err := selectSprints(sv.context, sv.sprintLister, sprintList.Sprints, sv.sprintNames)
+			if err != nil {
+				return errors.Wrap(err, "error selecting Sprints")
+			}
+			if err := sv.syncAll(selectedSprints); err != nil {
+				return errors.Wrap(err, "error syncing selected Sprints")
+			}
+			return sv.resetFormats()
+		}
 
-		// Pattern for filtering Sprints
-		// @TODO: Should this be part of the configuration?
-		r, err := regexp.Compile(fmt.Sprintf(`(?:[A-Z]{2,3})\s+Sprint\s+(%s)[-\s]?W?(\d{2})-W?(\d{2})`, year))
+		fmt.Printf("Filtering Sprints from year %s...\n", year)
 
-		if err != nil {
-			return errors.Wrap(err, "error comiling Sprint regex")
-		}
+		orderedSprintList := sprintsOfYear(sprintList.Sprints, sv.sprintNames, year)
 
-		var sprintLookupMap map[string]string = make(map[string]string, len(sprintList.Sprints))
-		var orderedSprintList []sprint = nil
+		var sprintLookupMap map[string]string = make(map[string]string, len(orderedSprintList))
 		var sprintPromptOptions []string = []string{}
 
-		for _, s := range sprintList.Sprints {
-			// filtering non-closed Sprint
-			if s.State != "CLOSED" {
-				continue
-			}
-			// filtering relevant Sprints by name pattern
-			if !r.MatchString(s.Name) && s.Name != "STR Sprint W51-W02(2021-2022)" {
-				continue
-			}
-			sprintLookupMap[s.Name] = strconv.Itoa(s.ID)
-			orderedSprintList = append(orderedSprintList, sprint{id: strconv.Itoa(s.ID), name: s.Name})
-			sprintPromptOptions = append(sprintPromptOptions, s.Name)
+		for _, s := range orderedSprintList {
+			sprintLookupMap[s.name] = s.id
+			sprintPromptOptions = append(sprintPromptOptions, s.name)
 		}
 
 		// Syncing the whole year
@@ -129,7 +160,9 @@ Example: jira-metrics sync --year 2021 [--all | --sprint-week 41-43]`,
 				Message: "Choose a Sprint:",
 				Options: sprintPromptOptions,
 			}
-			survey.AskOne(prompt, &selectedSprint)
+			if err := survey.AskOne(prompt, &selectedSprint); err != nil {
+				return errors.Wrap(err, "error choosing Sprint (use --sprint-id, --sprint-name or --latest when not running interactively)")
+			}
 
 			sprintID := sprintLookupMap[selectedSprint]
 
@@ -138,21 +171,7 @@ Example: jira-metrics sync --year 2021 [--all | --sprint-week 41-43]`,
 			}
 		}
 
-		// Format resetting
-		spreadSheetID := viper.GetString("GOOGLE_SPREADSHEET")
-		issuesGid := viper.GetInt64("GOOGLE_SPREADSHEET_TICKETS_GID")
-		sprintListGid := viper.GetInt64("GOOGLE_SPREADSHEET_SPRINTS_GID")
-
-		if err := sv.resetIssuesFormat(spreadSheetID, issuesGid); err != nil {
-			return err
-		}
-
-		if err := sv.resetSprintListFormat(spreadSheetID, sprintListGid); err != nil {
-			return err
-		}
-
-		fmt.Printf("ALL DONE!\n")
-		return nil
+		return sv.resetFormats()
 	},
 }
 
@@ -160,18 +179,70 @@ func init() {
 	rootCmd.AddCommand(syncCmd)
 
 	// flags and configuration settings.
-	syncCmd.Flags().StringVarP(&jiraProject, "project", "p", "", "Project ID from JIRA (required)")
-	syncCmd.MarkFlagRequired("project")
-	syncCmd.Flags().StringVarP(&year, "year", "y", "2021", "Year for filtering Sprints (required)")
-	syncCmd.MarkFlagRequired("year")
+	addSprintSelectionFlags(syncCmd)
 	syncCmd.Flags().BoolVarP(&all, "all", "a", false, "Sync ALL Sprints in the year")
+	syncCmd.Flags().BoolVarP(&upsert, "upsert", "u", false, "Update existing rows in place instead of appending duplicates")
+	syncCmd.Flags().IntVarP(&concurrency, "concurrency", "c", 1, "Number of Sprints fetched from JIRA in parallel")
+	syncCmd.Flags().IntVar(&issueConcurrency, "issue-concurrency", 1, "Number of issues of each Sprint fetched from JIRA in parallel")
+	syncCmd.Flags().BoolVar(&prune, "prune", false, "Delete rows of issues no longer in the Sprint report (requires --upsert)")
+	syncCmd.Flags().StringVar(&metricsMode, "metrics", "", "Measure issues by points or count them with issues (default METRICS_MODE)")
+	syncCmd.Flags().BoolVar(&strict, "strict", false, "Fail when the totals of the issue rows don't match the JIRA report")
 }
 
-// syncAll syncs all the Sprint from a list to the Google Spreadsheet
+// fetchedSprint is a Sprint report processed and ready to be written
+type fetchedSprint struct {
+	sprint sprint
+	report *jira.ReportResponse
+	rows   googlesheets.MySheetRowArray
+}
+
+// fetchResult is the outcome of fetching a Sprint in the background
+type fetchResult struct {
+	fetched *fetchedSprint
+	err     error
+}
+
+// syncAll syncs all the Sprint from a list to the Google Spreadsheet. Up to
+// --concurrency Sprints are fetched from JIRA in parallel, while they are
+// written to the Google Spreadsheet one by one in the order of the list.
 func (sv serviceWrapper) syncAll(sprints []sprint) error {
 
-	for _, sprint := range sprints {
-		if err := sv.syncSprint(sprint.id, sprint.name); err != nil {
+	ctx, cancel := context.WithCancel(sv.context)
+	defer cancel()
+
+	fetcher := sv
+	fetcher.context = ctx
+
+	results := make([]chan fetchResult, len(sprints))
+	for i := range results {
+		results[i] = make(chan fetchResult, 1)
+	}
+
+	go func() {
+		slots := make(chan struct{}, concurrencyLimit())
+		for i, s := range sprints {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				results[i] <- fetchResult{err: ctx.Err()}
+				continue
+			}
+			go func(i int, s sprint) {
+				defer func() { <-slots }()
+				fetched, err := fetcher.fetchSprint(s)
+				results[i] <- fetchResult{fetched: fetched, err: err}
+			}(i, s)
+		}
+	}()
+
+	for i, sprint := range sprints {
+		result := <-results[i]
+		if result.err != nil {
+			printSyncProgress(sv.context, sprints, i, false)
+			return errors.Wrapf(result.err, "error syncing Sprint %s", sprint.name)
+		}
+		if err := sv.writeSprint(result.fetched); err != nil {
+			printSyncProgress(sv.context, sprints, i, true)
 			return errors.Wrapf(err, "error syncing Sprint %s", sprint.name)
 		}
 	}
@@ -179,43 +250,108 @@ func (sv serviceWrapper) syncAll(sprints []sprint) error {
 	return nil
 }
 
+// printSyncProgress lists which Sprints were synced when the sync is
+// cancelled, partial tells whether the next Sprint was being written
+func printSyncProgress(ctx context.Context, sprints []sprint, synced int, partial bool) {
+
+	if ctx.Err() == nil {
+		return
+	}
+
+	names := func(list []sprint) string {
+		if len(list) == 0 {
+			return "none"
+		}
+		var result []string
+		for _, s := range list {
+			result = append(result, s.name)
+		}
+		return strings.Join(result, ", ")
+	}
+
+	fmt.Printf("Sync cancelled, %d of %d Sprints synced\n", synced, len(sprints))
+	fmt.Printf("  Synced: %s\n", names(sprints[:synced]))
+	fmt.Printf("  Not synced: %s\n", names(sprints[synced:]))
+	if partial && !upsert {
+		fmt.Printf("  %s may be partially written, sync it again with --upsert to avoid duplicated rows\n", sprints[synced].name)
+	}
+}
+
 // syncSprint syncs a single Sprint to the Google Spreadsheet
 func (sv serviceWrapper) syncSprint(sprintID, sprintName string) error {
 
-	sprintReportSrv, _ := sv.jiraClient.Report()
+	fetched, err := sv.fetchSprint(sprint{id: sprintID, name: sprintName})
+	if err != nil {
+		return err
+	}
+
+	return sv.writeSprint(fetched)
+}
 
-	sprintReport, err := sprintReportSrv.Get(sv.context, jiraProject, sprintID)
+// fetchSprint gets the Sprint report from JIRA and generates the issue rows
+func (sv serviceWrapper) fetchSprint(s sprint) (*fetchedSprint, error) {
+
+	sprintReport, err := sv.reportGetter.Get(sv.context, jiraProject, s.id)
 	if err != nil {
-		return errors.Wrap(err, "error getting Sprint report")
+		return nil, errors.Wrap(err, "error getting Sprint report")
 	}
 
 	issuesSrv, _ := sv.jiraClient.Issues()
+	searchSrv, _ := sv.jiraClient.Search()
 
-	issuesHelper := helper.NewIssuesHelper(issuesSrv)
+	issuesHelper := helper.NewIssuesHelper(issuesSrv, sv.sprintNames, sv.disciplineHelper).
+		WithConcurrency(issueConcurrencyLimit()).
+		WithSearch(searchSrv).
+		WithMeasure(sv.measure)
 
-	fmt.Printf("Processing report for %s...\n", sprintName)
+	fmt.Printf("Processing report for %s...\n", s.name)
 
-	allIssues, err := issuesHelper.ProcessReport(*sprintReport)
+	allIssues, err := issuesHelper.ProcessReport(sv.context, *sprintReport)
 	if err != nil {
-		return errors.Wrap(err, "error processing Sprint report")
+		return nil, errors.Wrap(err, "error processing Sprint report")
+	}
+
+	if mismatches := helper.Reconcile(*sprintReport, allIssues, sv.measure); len(mismatches) > 0 {
+		fmt.Printf("Totals of %s don't match the JIRA report:\n", s.name)
+		for _, m := range mismatches {
+			fmt.Printf("  %s\n", m)
+		}
+		if strict {
+			return nil, errors.Errorf("totals of %s don't match the JIRA report (--strict)", s.name)
+		}
 	}
 
+	return &fetchedSprint{sprint: s, report: sprintReport, rows: allIssues}, nil
+}
+
+// writeSprint writes the issue rows of a Sprint and adds it to the Sprint list
+func (sv serviceWrapper) writeSprint(fetched *fetchedSprint) error {
+
+	sprintName := fetched.sprint.name
+
 	fmt.Printf("Writing issues for %s in Google Sheets...\n", sprintName)
 
-	if _, err := sv.spreadSheetsHelper.Append(
-		sv.context,
-		viper.GetString("GOOGLE_SPREADSHEET"),
+	var issuesScope googlesheets.RowKeyFunc
+	if prune {
+		issuesScope = googlesheets.TicketRowSprint
+	}
+
+	if err := sv.writeRows(
 		viper.GetString("GOOGLE_SPREADSHEET_TICKETS_WR"),
-		allIssues.Convert(),
+		viper.GetInt64("GOOGLE_SPREADSHEET_TICKETS_GID"),
+		fetched.rows.Convert(),
+		googlesheets.TicketRowKey,
+		googlesheets.LegacyTicketRowKey,
+		issuesScope,
 	); err != nil {
 		return errors.Wrap(err, "error writing issues in GoogleSheets")
 	}
 
 	fmt.Printf("Adding Sprint to list %s in Google Sheets...\n", sprintName)
 
-	var sprintRows googlesheets.GoogleSheetValues = [][]interface{}{
-		{sprintName, sprintID, helper.SimplifySprintName(sprintName)},
-	}
+	sprintRows := googlesheets.SprintRowArray{
+		helper.SprintRow(*fetched.report, sv.sprintNames, sv.measure),
+	}.Convert()
 
 	if err := sv.addSprintsToList(sprintRows); err != nil {
 		return errors.Wrapf(err, "errors adding Sprint %s to list", sprintName)
@@ -224,16 +360,36 @@ func (sv serviceWrapper) syncSprint(sprintID, sprintName string) error {
 	return nil
 }
 
+// concurrencyLimit returns the number of Sprints fetched in parallel
+func concurrencyLimit() int {
+	if concurrency < 1 {
+		return 1
+	}
+	return concurrency
+}
+
+// issueConcurrencyLimit returns the number of issues of a Sprint fetched in
+// parallel, so up to concurrencyLimit() * issueConcurrencyLimit() requests
+// are in flight
+func issueConcurrencyLimit() int {
+	if issueConcurrency < 1 {
+		return 1
+	}
+	return issueConcurrency
+}
+
 // addSprintsToList adds a list of Sprints to a sheet in the Google Spreadsheet
 func (sv serviceWrapper) addSprintsToList(sprintRows googlesheets.GoogleSheetValues) error {
 
 	fmt.Printf("Adding Sprints to list in Google Sheets...\n")
 
-	if _, err := sv.spreadSheetsHelper.Append(
-		sv.context,
-		viper.GetString("GOOGLE_SPREADSHEET"),
+	if err := sv.writeRows(
 		viper.GetString("GOOGLE_SPREADSHEET_SPRINTS_WR"),
+		viper.GetInt64("GOOGLE_SPREADSHEET_SPRINTS_GID"),
 		sprintRows,
+		googlesheets.SprintRowKey,
+		nil,
+		nil,
 	); err != nil {
 		return errors.Wrap(err, "error adding Sprints to Sprint list Google Sheets")
 	}
@@ -241,6 +397,84 @@ func (sv serviceWrapper) addSprintsToList(sprintRows googlesheets.GoogleSheetVal
 	return nil
 }
 
+// writeRows appends rows to a range of the Google Spreadsheet or, in upsert
+// mode, updates the rows already present by key and appends the missing ones.
+// Rows without a key are matched by the legacy key when given. Existing rows
+// sharing the scope of an incoming row but missing from the
+// incoming rows are deleted when a scope is given.
+func (sv serviceWrapper) writeRows(
+	writeRange string,
+	gid int64,
+	rows googlesheets.GoogleSheetValues,
+	key googlesheets.RowKeyFunc,
+	legacyKey googlesheets.RowKeyFunc,
+	scope googlesheets.RowKeyFunc,
+) error {
+
+	spreadSheetID := viper.GetString("GOOGLE_SPREADSHEET")
+
+	if !upsert {
+		_, err := sv.spreadSheetsHelper.Append(sv.context, spreadSheetID, writeRange, rows)
+		return err
+	}
+
+	sheetRange, err := googlesheets.ParseSheetRange(writeRange)
+	if err != nil {
+		return err
+	}
+
+	existing, err := sv.spreadSheetsHelper.Get(sv.context, spreadSheetID, writeRange)
+	if err != nil {
+		return errors.Wrap(err, "error reading existing rows")
+	}
+
+	plan := googlesheets.PlanUpsert(sheetRange, existing, rows, key, legacyKey, scope)
+
+	fmt.Printf(
+		"Upserting %s: %d updated, %d added, %d deleted, %d unchanged...\n",
+		sheetRange.Sheet, len(plan.Updates), len(plan.Appends), len(plan.Deletes), plan.Unchanged,
+	)
+
+	// updates go first since deleting rows shifts the row numbers below them
+	if len(plan.Updates) > 0 {
+		if _, err := sv.spreadSheetsHelper.Update(sv.context, spreadSheetID, sheetRange, plan.Updates); err != nil {
+			return errors.Wrap(err, "error updating rows")
+		}
+	}
+
+	if len(plan.Deletes) > 0 {
+		if _, err := sv.spreadSheetsHelper.DeleteRows(sv.context, spreadSheetID, gid, plan.Deletes); err != nil {
+			return errors.Wrap(err, "error deleting rows")
+		}
+	}
+
+	if len(plan.Appends) > 0 {
+		if _, err := sv.spreadSheetsHelper.Append(sv.context, spreadSheetID, writeRange, plan.Appends); err != nil {
+			return errors.Wrap(err, "error appending rows")
+		}
+	}
+
+	return nil
+}
+
+// resetFormats resets the format of both the issues and the Sprint list sheets
+func (sv serviceWrapper) resetFormats() error {
+	spreadSheetID := viper.GetString("GOOGLE_SPREADSHEET")
+	issuesGid := viper.GetInt64("GOOGLE_SPREADSHEET_TICKETS_GID")
+	sprintListGid := viper.GetInt64("GOOGLE_SPREADSHEET_SPRINTS_GID")
+
+	if err := sv.resetIssuesFormat(spreadSheetID, issuesGid); err != nil {
+		return err
+	}
+
+	if err := sv.resetSprintListFormat(spreadSheetID, sprintListGid); err != nil {
+		return err
+	}
+
+	fmt.Printf("ALL DONE!\n")
+	return nil
+}
+
 // resetIssuesFormat sets the default style for rows of JIRA issues
 func (sv serviceWrapper) resetIssuesFormat(spreadSheetID string, gid int64) error {
 	fmt.Printf("Resetting format for issues list in Google Sheets...\n")
@@ -0,0 +1,254 @@
+package cache
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/jvalecillos/jira-metrics/pkg/jira"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultDirName is the directory created under the user cache directory
+	DefaultDirName = "jira-metrics"
+	// issuesFileName is the file storing the cached issues
+	issuesFileName = "issues.json"
+	// lockTimeout is how long Flush waits for another process holding the lock
+	lockTimeout = 10 * time.Second
+	// staleLockAge is the age of a lock file left behind by a crashed process
+	staleLockAge = time.Minute
+)
+
+// FileStore keeps issue payloads in memory and persists them as a JSON file.
+// It's safe for concurrent use, also by several processes sharing the file:
+// Flush merges with the file on disk under a lock file and replaces it
+// atomically. Entries older than the TTL are pruned on Flush.
+type FileStore struct {
+	mu      sync.RWMutex
+	path    string
+	ttl     time.Duration
+	entries map[string]jira.CachedIssue
+	dirty   bool
+}
+
+// Stats describes the content of the cache
+type Stats struct {
+	Path    string
+	Entries int
+	Size    int64
+	Oldest  time.Time
+	Newest  time.Time
+	Expired int
+}
+
+// DefaultDir returns the cache directory under the user cache directory
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, DefaultDirName), nil
+}
+
+// Open loads the issue cache from the given directory, starting empty when
+// there is no cache yet. Entries older than the TTL are pruned on Flush, a
+// zero TTL keeps them until the cache is cleared.
+func Open(dir string, ttl time.Duration) (*FileStore, error) {
+
+	s := &FileStore{
+		path: filepath.Join(dir, issuesFileName),
+		ttl:  ttl,
+	}
+
+	entries, err := readEntries(s.path)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		// a corrupted cache is not worth failing for, it gets rebuilt
+		entries = map[string]jira.CachedIssue{}
+		s.dirty = true
+	}
+	s.entries = entries
+
+	return s, nil
+}
+
+// readEntries reads the cached issues from the file, a missing file is an
+// empty cache and a corrupted one returns nil entries
+func readEntries(path string) (map[string]jira.CachedIssue, error) {
+
+	entries := map[string]jira.CachedIssue{}
+
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading issue cache")
+	}
+
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return nil, nil
+	}
+	return entries, nil
+}
+
+// Get returns the cached issue
+func (s *FileStore) Get(key string) (jira.CachedIssue, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, ok := s.entries[key]
+	return entry, ok
+}
+
+// Put stores an issue, it's persisted on the next Flush
+func (s *FileStore) Put(key string, issue jira.CachedIssue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = issue
+	s.dirty = true
+}
+
+// Clear removes all the cached issues, including the file
+func (s *FileStore) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries = map[string]jira.CachedIssue{}
+	s.dirty = false
+
+	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "error removing issue cache")
+	}
+	return nil
+}
+
+// Flush writes the cache to disk if it changed. Entries written meanwhile by
+// other processes are kept, the most recently fetched copy of an issue wins.
+func (s *FileStore) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.dirty {
+		return nil
+	}
+
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return errors.Wrap(err, "error creating cache directory")
+	}
+
+	unlock, err := lock(s.path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	onDisk, err := readEntries(s.path)
+	if err != nil {
+		return err
+	}
+	for key, entry := range onDisk {
+		if current, ok := s.entries[key]; !ok || entry.FetchedAt.After(current.FetchedAt) {
+			s.entries[key] = entry
+		}
+	}
+
+	now := time.Now()
+	for key, entry := range s.entries {
+		if entry.Expired(s.ttl, now) {
+			delete(s.entries, key)
+		}
+	}
+
+	b, err := json.Marshal(s.entries)
+	if err != nil {
+		return err
+	}
+
+	// writing to a temporary file first so readers never see a partial file
+	tmp, err := ioutil.TempFile(dir, issuesFileName+".*")
+	if err != nil {
+		return errors.Wrap(err, "error writing issue cache")
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "error writing issue cache")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "error writing issue cache")
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return errors.Wrap(err, "error writing issue cache")
+	}
+
+	s.dirty = false
+	return nil
+}
+
+// lock creates the lock file, waiting for other processes to release it. A
+// lock file older than staleLockAge is left by a crashed process and taken
+// over.
+func lock(path string) (unlock func(), err error) {
+
+	deadline := time.Now().Add(lockTimeout)
+
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, errors.Wrap(err, "error locking issue cache")
+		}
+
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.Errorf("issue cache is locked by another process, remove %s if it isn't running", path)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// Stats describes the cache, entries older than the TTL are counted as expired
+func (s *FileStore) Stats(ttl time.Duration) Stats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stats := Stats{
+		Path:    s.path,
+		Entries: len(s.entries),
+	}
+
+	if info, err := os.Stat(s.path); err == nil {
+		stats.Size = info.Size()
+	}
+
+	now := time.Now()
+	for _, entry := range s.entries {
+		if stats.Oldest.IsZero() || entry.FetchedAt.Before(stats.Oldest) {
+			stats.Oldest = entry.FetchedAt
+		}
+		if entry.FetchedAt.After(stats.Newest) {
+			stats.Newest = entry.FetchedAt
+		}
+		if entry.Expired(ttl, now) {
+			stats.Expired++
+		}
+	}
+
+	return stats
+}
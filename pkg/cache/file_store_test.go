@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jvalecillos/jira-metrics/pkg/jira"
+)
+
+func cachedIssue(key string, fetchedAt time.Time) jira.CachedIssue {
+	return jira.CachedIssue{Payload: json.RawMessage(`{"key":"` + key + `"}`), FetchedAt: fetchedAt}
+}
+
+func TestFileStoreFlushMerges(t *testing.T) {
+
+	dir := t.TempDir()
+	now := time.Now()
+
+	first, err := Open(dir, time.Hour)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	second, err := Open(dir, time.Hour)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	first.Put("STR-1", cachedIssue("STR-1", now.Add(-time.Minute)))
+	first.Put("STR-2", cachedIssue("STR-2", now.Add(-time.Minute)))
+	second.Put("STR-2", cachedIssue("STR-2", now))
+	second.Put("STR-3", cachedIssue("STR-3", now))
+
+	if err := second.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if err := first.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	got, err := Open(dir, time.Hour)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	for _, key := range []string{"STR-1", "STR-2", "STR-3"} {
+		if _, ok := got.Get(key); !ok {
+			t.Errorf("%s missing after both flushes", key)
+		}
+	}
+	// the most recently fetched copy wins
+	if entry, _ := got.Get("STR-2"); !entry.FetchedAt.Equal(now) {
+		t.Errorf("STR-2 fetched at %s, want %s", entry.FetchedAt, now)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, issuesFileName+".lock")); !os.IsNotExist(err) {
+		t.Errorf("lock file left behind: %v", err)
+	}
+}
+
+func TestFileStoreFlushPrunesExpired(t *testing.T) {
+
+	dir := t.TempDir()
+	now := time.Now()
+
+	store, err := Open(dir, time.Hour)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	store.Put("STR-1", cachedIssue("STR-1", now.Add(-2*time.Hour)))
+	store.Put("STR-2", cachedIssue("STR-2", now))
+
+	if err := store.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	if _, ok := store.Get("STR-1"); ok {
+		t.Error("expired STR-1 wasn't pruned")
+	}
+	if _, ok := store.Get("STR-2"); !ok {
+		t.Error("STR-2 was pruned")
+	}
+}
+
+func TestLockTakesOverStaleLock(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), issuesFileName+".lock")
+	if err := os.WriteFile(path, nil, 0600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	old := time.Now().Add(-2 * staleLockAge)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+
+	unlock, err := lock(path)
+	if err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+	unlock()
+}
@@ -0,0 +1,32 @@
+package googlesheets
+
+// CarryOverRow is a row of the "Chronic carry-overs" table
+type CarryOverRow struct {
+	TicketNumber   string  `json:"Ticket Number"`
+	Title          string  `json:"Title"`
+	Link           string  `json:"Link"`
+	CarryOvers     int     `json:"Carry-overs"`
+	LongestStreak  int     `json:"Longest Streak"`
+	SprintsTouched int     `json:"Sprints Touched"`
+	PointsSpilled  string  `json:"Points Spilled"`
+	TotalSpilled   float64 `json:"Total Spilled"`
+	FirstSprint    string  `json:"First Sprint"`
+	LastSprint     string  `json:"Last Sprint"`
+	CompletedIn    string  `json:"Completed In"`
+	// Metrics is the unit of the spilled points: points, or issues when
+	// counting them
+	Metrics string `json:"Metrics"`
+}
+
+type CarryOverRowArray []CarryOverRow
+
+func (m CarryOverRowArray) Convert() GoogleSheetValues {
+
+	result := make(GoogleSheetValues, len(m))
+
+	for i, s := range m {
+		result[i] = structValues(s)
+	}
+
+	return result
+}
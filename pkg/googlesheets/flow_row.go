@@ -0,0 +1,31 @@
+package googlesheets
+
+// FlowRow is a row of the "Flow" table, times are in days and left empty
+// while the issue is not done
+type FlowRow struct {
+	Sprint       string `json:"Sprint"`
+	TicketNumber string `json:"Ticket Number"`
+	Title        string `json:"Title"`
+	Link         string `json:"Link"`
+	IssueType    string `json:"Issue Type"`
+	Status       string `json:"Status"`
+	Created      string `json:"Created"`
+	Started      string `json:"Started"`
+	Done         string `json:"Done"`
+	LeadTime     string `json:"Lead Time"`
+	CycleTime    string `json:"Cycle Time"`
+	TimeInStatus string `json:"Time In Status"`
+}
+
+type FlowRowArray []FlowRow
+
+func (m FlowRowArray) Convert() GoogleSheetValues {
+
+	result := make(GoogleSheetValues, len(m))
+
+	for i, s := range m {
+		result[i] = structValues(s)
+	}
+
+	return result
+}
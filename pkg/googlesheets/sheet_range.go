@@ -0,0 +1,53 @@
+package googlesheets
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SheetRange is a parsed A1 notation range like "Tickets!A2:K"
+type SheetRange struct {
+	Sheet       string
+	StartColumn string
+	StartRow    int64
+}
+
+var a1StartRegex = regexp.MustCompile(`^([A-Za-z]+)(\d+)`)
+
+// ParseSheetRange parses the beginning of an A1 notation range
+func ParseSheetRange(a1 string) (SheetRange, error) {
+	parts := strings.SplitN(a1, "!", 2)
+	if len(parts) != 2 {
+		return SheetRange{}, errors.Errorf("range %q has no sheet name", a1)
+	}
+
+	matches := a1StartRegex.FindStringSubmatch(parts[1])
+	if len(matches) != 3 {
+		return SheetRange{}, errors.Errorf("range %q has no starting cell", a1)
+	}
+
+	row, err := strconv.ParseInt(matches[2], 10, 64)
+	if err != nil {
+		return SheetRange{}, errors.Wrapf(err, "invalid starting row in range %q", a1)
+	}
+
+	return SheetRange{
+		Sheet:       parts[0],
+		StartColumn: strings.ToUpper(matches[1]),
+		StartRow:    row,
+	}, nil
+}
+
+// RowNumber returns the 1-based sheet row for the given 0-based index within the range
+func (r SheetRange) RowNumber(index int) int64 {
+	return r.StartRow + int64(index)
+}
+
+// RowRange returns the A1 notation of a single row starting at the range column
+func (r SheetRange) RowRange(rowNumber int64) string {
+	return fmt.Sprintf("%s!%s%d", r.Sheet, r.StartColumn, rowNumber)
+}
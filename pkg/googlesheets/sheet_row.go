@@ -3,17 +3,30 @@ package googlesheets
 import "reflect"
 
 type MySheetRow struct {
-	Sprint       string `json:"Sprint"`
-	Dicipline    string `json:"Dicipline"`
-	TicketNumber string `json:"Ticket Number"`
-	Title        string `json:"Title"`
-	Link         string `json:"Link"`
-	Commited     int    `json:"Commited"`
-	Dropped      int    `json:"Dropped"`
-	Added        int    `json:"Added"`
-	Adjusted     int    `json:"Adjusted"`
-	CarriedOver  int    `json:"Carried Over"`
-	Completed    int    `json:"Completed"`
+	Sprint       string  `json:"Sprint"`
+	Dicipline    string  `json:"Dicipline"`
+	TicketNumber string  `json:"Ticket Number"`
+	Title        string  `json:"Title"`
+	Link         string  `json:"Link"`
+	Commited     float64 `json:"Commited"`
+	Dropped      float64 `json:"Dropped"`
+	Added        float64 `json:"Added"`
+	Adjusted     float64 `json:"Adjusted"`
+	CarriedOver  float64 `json:"Carried Over"`
+	Completed    float64 `json:"Completed"`
+	SprintID     string  `json:"Sprint ID"`
+	// CurrentEstimate is the estimate at the end of the Sprint, or when the
+	// issue was completed or removed, and Reestimated the change from the
+	// original estimate
+	CurrentEstimate float64 `json:"Current Estimate"`
+	Reestimated     float64 `json:"Re-estimated"`
+	// CompletedInAnotherSprint is the original estimate of issues of the
+	// Sprint which were completed in another one
+	CompletedInAnotherSprint float64 `json:"Completed In Another Sprint"`
+	// Unestimated is 1 for issues without estimate, so they can be summed
+	Unestimated int `json:"Unestimated"`
+	// Metrics is the unit of the row: points, or issues when counting them
+	Metrics string `json:"Metrics"`
 }
 
 type MySheetRowArray []MySheetRow
@@ -24,23 +37,74 @@ func (m MySheetRowArray) Convert() GoogleSheetValues {
 
 	result := make(GoogleSheetValues, len(m))
 
-	i := 0
-	for _, s := range m {
+	for i, s := range m {
+		result[i] = structValues(s)
+	}
+
+	return result
+}
+
+// structValues transforms a sheet struct in a generic interface array
+// ([]interface{}) with a value per field
+func structValues(s interface{}) []interface{} {
+
+	sValue := reflect.ValueOf(s)
+	values := make([]interface{}, sValue.NumField())
+
+	for j := 0; j < sValue.NumField(); j++ {
+		// copy struct field value into interface
+		if sValue.Field(j).CanInterface() {
+			values[j] = sValue.Field(j).Interface()
+		}
+	}
+
+	return values
+}
 
-		// transforming the sheet struct in a generic interface array ([]interface{})
-		sValue := reflect.ValueOf(s)
-		values := make([]interface{}, sValue.NumField())
+// Headers returns the column names of a sheet struct, taken from the json tags
+// of its fields
+func Headers(s interface{}) []string {
 
-		for j := 0; j < sValue.NumField(); j++ {
-			// copy struct field value into interface
-			if sValue.Field(j).CanInterface() {
-				values[j] = sValue.Field(j).Interface()
-			}
+	sType := reflect.TypeOf(s)
+	headers := make([]string, sType.NumField())
+
+	for j := 0; j < sType.NumField(); j++ {
+		headers[j] = sType.Field(j).Tag.Get("json")
+		if headers[j] == "" {
+			headers[j] = sType.Field(j).Name
 		}
+	}
+
+	return headers
+}
+
+// TicketRowKey identifies a ticket row by Sprint ID and ticket number
+var TicketRowKey = ColumnKey(columnIndex("SprintID"), columnIndex("TicketNumber"))
+
+// LegacyTicketRowKey identifies a ticket row written before the Sprint ID
+// column existed by Sprint label and ticket number
+var LegacyTicketRowKey = ColumnKey(columnIndex("Sprint"), columnIndex("TicketNumber"))
 
-		result[i] = values
-		i++
+// TicketRowSprint identifies the Sprint a ticket row belongs to
+var TicketRowSprint = ColumnKey(columnIndex("SprintID"))
+
+// SprintRowKey identifies a row of the Sprint list by Sprint ID
+var SprintRowKey = ColumnKey(sprintColumnIndex("SprintID"))
+
+// sprintColumnIndex returns the position of a SprintRow field in the converted row
+func sprintColumnIndex(fieldName string) int {
+	f, ok := reflect.TypeOf(SprintRow{}).FieldByName(fieldName)
+	if !ok {
+		panic("unknown SprintRow field " + fieldName)
 	}
+	return f.Index[0]
+}
 
-	return result
+// columnIndex returns the position of a MySheetRow field in the converted row
+func columnIndex(fieldName string) int {
+	f, ok := reflect.TypeOf(MySheetRow{}).FieldByName(fieldName)
+	if !ok {
+		panic("unknown MySheetRow field " + fieldName)
+	}
+	return f.Index[0]
 }
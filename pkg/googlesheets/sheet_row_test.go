@@ -0,0 +1,46 @@
+package googlesheets
+
+import (
+	"io/ioutil"
+	"regexp"
+	"testing"
+)
+
+// columnLetter returns the letter of the nth column (1 is A), enough for the
+// widths of the sheets written
+func columnLetter(n int) string {
+	if n <= 26 {
+		return string(rune('A' + n - 1))
+	}
+	return columnLetter((n-1)/26) + columnLetter((n-1)%26+1)
+}
+
+func TestExampleRangesSpanRows(t *testing.T) {
+
+	b, err := ioutil.ReadFile("../../.jira-metrics.yaml.example")
+	if err != nil {
+		t.Fatalf("reading the example configuration: %v", err)
+	}
+
+	tests := []struct {
+		setting string
+		row     interface{}
+	}{
+		{"GOOGLE_SPREADSHEET_TICKETS_WR", MySheetRow{}},
+		{"GOOGLE_SPREADSHEET_SPRINTS_WR", SprintRow{}},
+		{"GOOGLE_SPREADSHEET_CARRYOVERS_WR", CarryOverRow{}},
+		{"GOOGLE_SPREADSHEET_VELOCITY_WR", VelocityRow{}},
+		{"GOOGLE_SPREADSHEET_FLOW_WR", FlowRow{}},
+	}
+
+	for _, tt := range tests {
+		m := regexp.MustCompile(tt.setting + `: [^!\n]+!A2:([A-Z]+)`).FindSubmatch(b)
+		if m == nil {
+			t.Errorf("%s not found in the example configuration", tt.setting)
+			continue
+		}
+		if want := columnLetter(len(Headers(tt.row))); string(m[1]) != want {
+			t.Errorf("%s ends at column %s, want %s", tt.setting, m[1], want)
+		}
+	}
+}
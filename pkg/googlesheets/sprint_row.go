@@ -0,0 +1,46 @@
+package googlesheets
+
+// SprintRow is a row of the Sprint list with the aggregated metrics of the
+// Sprint. Ratios are empty when they can't be computed.
+type SprintRow struct {
+	Name               string  `json:"Name"`
+	SprintID           string  `json:"Sprint ID"`
+	Sprint             string  `json:"Sprint"`
+	Commited           float64 `json:"Commited"`
+	Added              float64 `json:"Added"`
+	Dropped            float64 `json:"Dropped"`
+	Adjusted           float64 `json:"Adjusted"`
+	Completed          float64 `json:"Completed"`
+	CarriedOver        float64 `json:"Carried Over"`
+	SayDo              string  `json:"Say/Do"`
+	ScopeChange        string  `json:"Scope Change"`
+	CompletionRate     string  `json:"Completion Rate"`
+	CompletedIssues    int     `json:"Completed Issues"`
+	NotCompletedIssues int     `json:"Not Completed Issues"`
+	DroppedIssues      int     `json:"Dropped Issues"`
+	AddedIssues        int     `json:"Added Issues"`
+	Inflated           float64 `json:"Inflated"`
+	Deflated           float64 `json:"Deflated"`
+	ReestimatedIssues  int     `json:"Re-estimated Issues"`
+	ReestimatedShare   string  `json:"Re-estimated Share"`
+
+	CompletedInAnotherSprint       float64 `json:"Completed In Another Sprint"`
+	CompletedInAnotherSprintIssues int     `json:"Completed In Another Sprint Issues"`
+
+	UnestimatedIssues int `json:"Unestimated Issues"`
+	// Metrics is the unit of the row: points, or issues when counting them
+	Metrics string `json:"Metrics"`
+}
+
+type SprintRowArray []SprintRow
+
+func (m SprintRowArray) Convert() GoogleSheetValues {
+
+	result := make(GoogleSheetValues, len(m))
+
+	for i, s := range m {
+		result[i] = structValues(s)
+	}
+
+	return result
+}
@@ -0,0 +1,165 @@
+package googlesheets
+
+import "fmt"
+
+// RowKeyFunc identifies a row, rows with the same key are considered the same record
+type RowKeyFunc func(row []interface{}) string
+
+// RowUpdate is a row to be overwritten in place
+type RowUpdate struct {
+	RowNumber int64
+	Values    []interface{}
+}
+
+// UpsertPlan contains the changes needed to make a sheet range reflect the incoming rows
+type UpsertPlan struct {
+	Updates   []RowUpdate
+	Appends   GoogleSheetValues
+	Deletes   []int64
+	Unchanged int
+}
+
+// PlanUpsert compares the rows already present in a range with the incoming
+// ones. Changed rows are updated in place and missing rows are appended.
+// Existing rows without a key (e.g. written before the key columns existed)
+// are matched by the legacy key when given, so they get updated and keyed
+// instead of duplicated. When scope is given, existing rows in the same scope
+// as any incoming row (e.g. the same Sprint) which are not part of the
+// incoming rows are deleted.
+func PlanUpsert(
+	sheetRange SheetRange,
+	existing GoogleSheetValues,
+	incoming GoogleSheetValues,
+	key RowKeyFunc,
+	legacyKey RowKeyFunc,
+	scope RowKeyFunc,
+) UpsertPlan {
+
+	var plan UpsertPlan
+
+	// keys and scopes of the existing rows, legacy rows take the ones of the
+	// incoming row they match
+	keys := make([]string, len(existing))
+	scopes := make([]string, len(existing))
+
+	var legacyIndex map[string]int
+	if legacyKey != nil {
+		legacyIndex = make(map[string]int, len(incoming))
+		for i, row := range incoming {
+			k := legacyKey(row)
+			if _, ok := legacyIndex[k]; k != "" && !ok {
+				legacyIndex[k] = i
+			}
+		}
+	}
+
+	for i, row := range existing {
+		keys[i] = key(row)
+		if scope != nil {
+			scopes[i] = scope(row)
+		}
+		if keys[i] != "" || legacyKey == nil {
+			continue
+		}
+		k := legacyKey(row)
+		if j, ok := legacyIndex[k]; k != "" && ok {
+			keys[i] = key(incoming[j])
+			if scope != nil {
+				scopes[i] = scope(incoming[j])
+			}
+		}
+	}
+
+	existingIndex := make(map[string]int, len(existing))
+	for i, k := range keys {
+		if k == "" {
+			continue
+		}
+		// keeping the first occurrence, duplicates from previous appends get pruned
+		if _, ok := existingIndex[k]; !ok {
+			existingIndex[k] = i
+		}
+	}
+
+	incomingKeys := make(map[string]bool, len(incoming))
+	incomingScopes := make(map[string]bool)
+
+	for _, row := range incoming {
+		k := key(row)
+		incomingKeys[k] = true
+		if scope != nil {
+			incomingScopes[scope(row)] = true
+		}
+
+		i, ok := existingIndex[k]
+		if !ok {
+			plan.Appends = append(plan.Appends, row)
+			continue
+		}
+
+		if rowsEqual(existing[i], row) {
+			plan.Unchanged++
+			continue
+		}
+
+		plan.Updates = append(plan.Updates, RowUpdate{
+			RowNumber: sheetRange.RowNumber(i),
+			Values:    row,
+		})
+	}
+
+	if scope == nil {
+		return plan
+	}
+
+	for i, k := range keys {
+		if k == "" || !incomingScopes[scopes[i]] {
+			continue
+		}
+		if !incomingKeys[k] || existingIndex[k] != i {
+			plan.Deletes = append(plan.Deletes, sheetRange.RowNumber(i))
+		}
+	}
+
+	return plan
+}
+
+// rowsEqual compares rows by their printed values, the Sheets API omits
+// trailing empty cells and returns every number as float64
+func rowsEqual(existing, incoming []interface{}) bool {
+	if len(existing) > len(incoming) {
+		return false
+	}
+	for i := range incoming {
+		if cellString(existing, i) != cellString(incoming, i) {
+			return false
+		}
+	}
+	return true
+}
+
+// cellString returns the printed value of a cell, empty when out of range
+func cellString(row []interface{}, index int) string {
+	if index >= len(row) || row[index] == nil {
+		return ""
+	}
+	return fmt.Sprint(row[index])
+}
+
+// ColumnKey returns a RowKeyFunc joining the values of the given columns
+func ColumnKey(columns ...int) RowKeyFunc {
+	return func(row []interface{}) string {
+		key := ""
+		for i, column := range columns {
+			value := cellString(row, column)
+			if value == "" {
+				return ""
+			}
+			if i > 0 {
+				key += "|"
+			}
+			key += value
+		}
+		return key
+	}
+}
@@ -0,0 +1,200 @@
+package googlesheets
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testRange is a range whose first row is the second row of the sheet
+var testRange = SheetRange{Sheet: "Tickets", StartColumn: "A", StartRow: 2}
+
+// test rows are [scope, key, value]
+var (
+	testKey   = ColumnKey(0, 1)
+	testScope = ColumnKey(0)
+)
+
+func TestPlanUpsert(t *testing.T) {
+
+	existing := GoogleSheetValues{
+		{"S1", "T-1", float64(3)},
+		{"S1", "T-2", float64(5)},
+	}
+	incoming := GoogleSheetValues{
+		{"S1", "T-1", 3},
+		{"S1", "T-2", 8},
+		{"S1", "T-3", 1},
+	}
+
+	got := PlanUpsert(testRange, existing, incoming, testKey, nil, nil)
+
+	want := UpsertPlan{
+		Updates:   []RowUpdate{{RowNumber: 3, Values: []interface{}{"S1", "T-2", 8}}},
+		Appends:   GoogleSheetValues{{"S1", "T-3", 1}},
+		Unchanged: 1,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PlanUpsert() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPlanUpsertPrune(t *testing.T) {
+
+	existing := GoogleSheetValues{
+		{"S1", "T-1", float64(3)},
+		{"S2", "T-9", float64(2)},
+		{"S1", "T-2", float64(5)},
+		{"S1", "T-1", float64(3)},
+	}
+	incoming := GoogleSheetValues{
+		{"S1", "T-1", 3},
+	}
+
+	tests := []struct {
+		name        string
+		scope       RowKeyFunc
+		wantDeletes []int64
+	}{
+		// without scope nothing is deleted, not even duplicates
+		{"without scope", nil, nil},
+		// T-2 left the Sprint and the second T-1 is a duplicate, S2 is untouched
+		{"with scope", testScope, []int64{4, 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PlanUpsert(testRange, existing, incoming, testKey, nil, tt.scope)
+
+			if !reflect.DeepEqual(got.Deletes, tt.wantDeletes) {
+				t.Errorf("Deletes = %v, want %v", got.Deletes, tt.wantDeletes)
+			}
+			if len(got.Updates) != 0 || len(got.Appends) != 0 || got.Unchanged != 1 {
+				t.Errorf("PlanUpsert() = %+v, want only the first T-1 unchanged", got)
+			}
+		})
+	}
+}
+
+func TestPlanUpsertKeylessRows(t *testing.T) {
+
+	existing := GoogleSheetValues{
+		{"S1", "", "a note"},
+		{"S1", "T-1", float64(3)},
+		{},
+	}
+	incoming := GoogleSheetValues{
+		{"S1", "T-1", 3},
+		{"S1", "", 4},
+	}
+
+	got := PlanUpsert(testRange, existing, incoming, testKey, nil, testScope)
+
+	want := UpsertPlan{
+		Appends:   GoogleSheetValues{{"S1", "", 4}},
+		Unchanged: 1,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PlanUpsert() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPlanUpsertLegacyRows(t *testing.T) {
+
+	row := func(sprint, ticket string, completed float64, sprintID string) MySheetRow {
+		return MySheetRow{Sprint: sprint, TicketNumber: ticket, Completed: completed, SprintID: sprintID}
+	}
+
+	// rows written before the Sprint ID column, the second T-1 is a duplicate
+	existing := MySheetRowArray{
+		row("W41-43", "T-1", 3, ""),
+		row("W44-46", "T-1", 2, ""),
+		row("W41-43", "T-2", 5, ""),
+		row("W41-43", "T-1", 3, ""),
+	}.Convert()
+	for i := range existing {
+		existing[i] = existing[i][:columnIndex("SprintID")]
+	}
+
+	incoming := MySheetRowArray{
+		row("W41-43", "T-1", 3, "101"),
+		row("W41-43", "T-3", 1, "101"),
+	}.Convert()
+
+	got := PlanUpsert(testRange, existing, incoming, TicketRowKey, LegacyTicketRowKey, TicketRowSprint)
+
+	want := UpsertPlan{
+		// the legacy row gets its Sprint ID instead of a duplicate
+		Updates: []RowUpdate{{RowNumber: 2, Values: incoming[0]}},
+		Appends: GoogleSheetValues{incoming[1]},
+		// the duplicate is pruned, the unmatched legacy rows are left alone
+		Deletes: []int64{5},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PlanUpsert() = %+v, want %+v", got, want)
+	}
+
+	// once keyed the rows are matched by Sprint ID
+	existing[0] = incoming[0]
+	got = PlanUpsert(testRange, existing[:1], incoming[:1], TicketRowKey, LegacyTicketRowKey, TicketRowSprint)
+	if got.Unchanged != 1 || len(got.Updates)+len(got.Appends)+len(got.Deletes) != 0 {
+		t.Errorf("PlanUpsert() of keyed rows = %+v, want unchanged", got)
+	}
+}
+
+func TestRowsEqual(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		existing []interface{}
+		incoming []interface{}
+		want     bool
+	}{
+		{"numbers read back as float64", []interface{}{"T-1", float64(3)}, []interface{}{"T-1", 3}, true},
+		{"trailing empty cells omitted", []interface{}{"T-1"}, []interface{}{"T-1", ""}, true},
+		{"changed value", []interface{}{"T-1", float64(3)}, []interface{}{"T-1", 5}, false},
+		{"extra existing cells", []interface{}{"T-1", float64(3), "x"}, []interface{}{"T-1", 3}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rowsEqual(tt.existing, tt.incoming); got != tt.want {
+				t.Errorf("rowsEqual(%v, %v) = %v, want %v", tt.existing, tt.incoming, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColumnKey(t *testing.T) {
+
+	key := ColumnKey(2, 0)
+
+	if got := key([]interface{}{"T-1", "x", float64(101)}); got != "101|T-1" {
+		t.Errorf("key = %q, want %q", got, "101|T-1")
+	}
+	if got := key([]interface{}{"T-1", "x"}); got != "" {
+		t.Errorf("key of a row missing a column = %q, want empty", got)
+	}
+}
+
+func TestSheetRange(t *testing.T) {
+
+	r, err := ParseSheetRange("Tickets!B3:L")
+	if err != nil {
+		t.Fatalf("ParseSheetRange: %v", err)
+	}
+
+	want := SheetRange{Sheet: "Tickets", StartColumn: "B", StartRow: 3}
+	if r != want {
+		t.Errorf("ParseSheetRange() = %+v, want %+v", r, want)
+	}
+	if got := r.RowNumber(2); got != 5 {
+		t.Errorf("RowNumber(2) = %d, want 5", got)
+	}
+	if got := r.RowRange(5); got != "Tickets!B5" {
+		t.Errorf("RowRange(5) = %q, want %q", got, "Tickets!B5")
+	}
+
+	if _, err := ParseSheetRange("A2:L"); err == nil {
+		t.Error("ParseSheetRange without sheet name didn't fail")
+	}
+}
@@ -0,0 +1,33 @@
+package googlesheets
+
+// VelocityRow is a row of the velocity series, rolling values are computed
+// over the window of Sprints ending with the Sprint of the row. Ratios are
+// empty when they can't be computed.
+type VelocityRow struct {
+	Sprint         string  `json:"Sprint"`
+	SprintID       string  `json:"Sprint ID"`
+	Commited       float64 `json:"Commited"`
+	Completed      float64 `json:"Completed"`
+	SayDo          string  `json:"Say/Do"`
+	Window         int     `json:"Window"`
+	RollingAverage float64 `json:"Rolling Average"`
+	RollingMedian  float64 `json:"Rolling Median"`
+	StdDev         float64 `json:"Std Dev"`
+	CV             string  `json:"CV"`
+	Predictability string  `json:"Predictability"`
+	// Metrics is the unit of the row: points, or issues when counting them
+	Metrics string `json:"Metrics"`
+}
+
+type VelocityRowArray []VelocityRow
+
+func (m VelocityRowArray) Convert() GoogleSheetValues {
+
+	result := make(GoogleSheetValues, len(m))
+
+	for i, s := range m {
+		result[i] = structValues(s)
+	}
+
+	return result
+}
@@ -0,0 +1,165 @@
+package helper
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jvalecillos/jira-metrics/pkg/googlesheets"
+	"github.com/jvalecillos/jira-metrics/pkg/jira"
+)
+
+// SpilledPoints are the points of an issue left unfinished at the end of a
+// Sprint, or 1 when counting issues
+type SpilledPoints struct {
+	Sprint string  `json:"sprint"`
+	Points float64 `json:"points"`
+}
+
+// CarryOverChain follows an issue across consecutive Sprint reports
+type CarryOverChain struct {
+	Key     string `json:"key"`
+	Summary string `json:"summary"`
+	// CarryOvers is the number of Sprints the issue was carried over from
+	CarryOvers int `json:"carryOvers"`
+	// LongestStreak is the highest number of consecutive Sprints the issue
+	// was carried over from
+	LongestStreak int `json:"longestStreak"`
+	// SprintsTouched is the number of Sprints the issue was part of
+	SprintsTouched int             `json:"sprintsTouched"`
+	Spilled        []SpilledPoints `json:"spilled"`
+	TotalSpilled   float64         `json:"totalSpilled"`
+	FirstSprint    string          `json:"firstSprint"`
+	LastSprint     string          `json:"lastSprint"`
+	// CompletedIn is the Sprint the issue was completed in, empty while open
+	CompletedIn string `json:"completedIn,omitempty"`
+}
+
+// CarryOverHelper links the same issues across Sprint reports
+type CarryOverHelper struct {
+	sprintNames SprintNameHelper
+	browseURL   string
+	measure     Measure
+}
+
+func NewCarryOverHelper(sprintNames SprintNameHelper, browseURL string, measure Measure) CarryOverHelper {
+	return CarryOverHelper{sprintNames: sprintNames, browseURL: browseURL, measure: measure}
+}
+
+// Track builds the carry-over chains of the issues in the given reports,
+// which must be sorted from the oldest to the newest Sprint. Only issues
+// carried over at least minCarryOvers times are returned, the ones carried
+// over for longer first.
+func (c CarryOverHelper) Track(reports []jira.ReportResponse, minCarryOvers int) []CarryOverChain {
+
+	chains := map[string]*CarryOverChain{}
+	// index of the last report the issue was carried over from
+	lastCarried := map[string]int{}
+	streaks := map[string]int{}
+
+	chainOf := func(issue jira.Issue, sprint string) *CarryOverChain {
+		chain, ok := chains[issue.Key]
+		if !ok {
+			chain = &CarryOverChain{Key: issue.Key, FirstSprint: sprint}
+			chains[issue.Key] = chain
+		}
+		chain.Summary = issue.Summary
+		chain.LastSprint = sprint
+		chain.SprintsTouched++
+		return chain
+	}
+
+	for i, report := range reports {
+		sprint := c.sprintNames.Simplify(report.Sprint.Name)
+
+		for _, issue := range report.Contents.IssuesNotCompletedInCurrentSprint {
+			chain := chainOf(issue, sprint)
+
+			points := c.measure.Current(issue)
+			chain.CarryOvers++
+			chain.Spilled = append(chain.Spilled, SpilledPoints{Sprint: sprint, Points: points})
+			chain.TotalSpilled += points
+			chain.CompletedIn = ""
+
+			if last, ok := lastCarried[issue.Key]; ok && last == i-1 {
+				streaks[issue.Key]++
+			} else {
+				streaks[issue.Key] = 1
+			}
+			lastCarried[issue.Key] = i
+
+			if streaks[issue.Key] > chain.LongestStreak {
+				chain.LongestStreak = streaks[issue.Key]
+			}
+		}
+
+		for _, issue := range report.Contents.CompletedIssues {
+			chainOf(issue, sprint).CompletedIn = sprint
+		}
+
+		for _, issue := range report.Contents.PuntedIssues {
+			chainOf(issue, sprint)
+		}
+
+		for _, issue := range report.Contents.IssuesCompletedInAnotherSprint {
+			chainOf(issue, sprint)
+		}
+	}
+
+	var result []CarryOverChain
+	for _, chain := range chains {
+		if chain.CarryOvers >= minCarryOvers && chain.CarryOvers > 0 {
+			result = append(result, *chain)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.LongestStreak != b.LongestStreak {
+			return a.LongestStreak > b.LongestStreak
+		}
+		if a.CarryOvers != b.CarryOvers {
+			return a.CarryOvers > b.CarryOvers
+		}
+		if a.TotalSpilled != b.TotalSpilled {
+			return a.TotalSpilled > b.TotalSpilled
+		}
+		return a.Key < b.Key
+	})
+
+	return result
+}
+
+// Rows generates the rows of the "Chronic carry-overs" table
+func (c CarryOverHelper) Rows(chains []CarryOverChain) googlesheets.CarryOverRowArray {
+
+	rows := make(googlesheets.CarryOverRowArray, len(chains))
+
+	for i, chain := range chains {
+		rows[i] = googlesheets.CarryOverRow{
+			TicketNumber:   chain.Key,
+			Title:          chain.Summary,
+			Link:           jiraLink(c.browseURL, chain.Key, chain.Summary),
+			CarryOvers:     chain.CarryOvers,
+			LongestStreak:  chain.LongestStreak,
+			SprintsTouched: chain.SprintsTouched,
+			PointsSpilled:  chain.SpilledSummary(FullPrecision),
+			TotalSpilled:   chain.TotalSpilled,
+			FirstSprint:    chain.FirstSprint,
+			LastSprint:     chain.LastSprint,
+			CompletedIn:    chain.CompletedIn,
+			Metrics:        c.measure.Mode(),
+		}
+	}
+
+	return rows
+}
+
+// SpilledSummary lists the points spilled per Sprint as "Sprint: points"
+func (c CarryOverChain) SpilledSummary(points PointsFormat) string {
+	parts := make([]string, len(c.Spilled))
+	for i, s := range c.Spilled {
+		parts[i] = fmt.Sprintf("%s: %s", s.Sprint, points.Format(s.Points))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,73 @@
+package helper
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jvalecillos/jira-metrics/pkg/jira"
+)
+
+func TestCarryOverHelperTrack(t *testing.T) {
+
+	issue := func(key string, points float64) jira.Issue {
+		current := jira.Statistic{StatFieldValue: jira.Fieldvalue{Value: points}}
+		return jira.Issue{Key: key, CurrentEstimateStatistic: current}
+	}
+	report := func(name string, notCompleted []jira.Issue, completed ...jira.Issue) jira.ReportResponse {
+		return jira.ReportResponse{
+			Sprint: jira.Sprint{Name: name},
+			Contents: jira.Contents{
+				IssuesNotCompletedInCurrentSprint: notCompleted,
+				CompletedIssues:                   completed,
+			},
+		}
+	}
+
+	// STR-1 spills twice with a growing estimate, STR-2 only once
+	reports := []jira.ReportResponse{
+		report("S1", []jira.Issue{issue("STR-1", 3), issue("STR-2", 2)}),
+		report("S2", []jira.Issue{issue("STR-1", 5)}, issue("STR-2", 2)),
+		report("S3", nil, issue("STR-1", 5)),
+	}
+
+	tests := []struct {
+		mode        string
+		wantSpilled []SpilledPoints
+		wantTotal   float64
+	}{
+		{MetricsPoints, []SpilledPoints{{"S1", 3}, {"S2", 5}}, 8},
+		{MetricsIssues, []SpilledPoints{{"S1", 1}, {"S2", 1}}, 2},
+	}
+
+	sprintNames, err := NewSprintNameHelper("", "", nil, nil)
+	if err != nil {
+		t.Fatalf("NewSprintNameHelper: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			measure, err := NewMeasure(tt.mode)
+			if err != nil {
+				t.Fatalf("NewMeasure: %v", err)
+			}
+			carryOvers := NewCarryOverHelper(sprintNames, "", measure)
+
+			chains := carryOvers.Track(reports, 2)
+			if len(chains) != 1 || chains[0].Key != "STR-1" {
+				t.Fatalf("Track() = %+v, want only STR-1", chains)
+			}
+			chain := chains[0]
+			if !reflect.DeepEqual(chain.Spilled, tt.wantSpilled) || chain.TotalSpilled != tt.wantTotal {
+				t.Errorf("spilled = %v (%g), want %v (%g)", chain.Spilled, chain.TotalSpilled, tt.wantSpilled, tt.wantTotal)
+			}
+			if chain.LongestStreak != 2 || chain.CompletedIn != "S3" {
+				t.Errorf("streak = %d, completed in %q, want 2 and S3", chain.LongestStreak, chain.CompletedIn)
+			}
+
+			rows := carryOvers.Rows(chains)
+			if rows[0].Metrics != tt.mode || rows[0].TotalSpilled != tt.wantTotal {
+				t.Errorf("row = %+v, want %s metrics", rows[0], tt.mode)
+			}
+		})
+	}
+}
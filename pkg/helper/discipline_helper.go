@@ -0,0 +1,382 @@
+package helper
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jvalecillos/jira-metrics/pkg/jira"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DisciplineModeFirstMatch picks the discipline of the first matching rule
+	DisciplineModeFirstMatch = "first-match"
+	// DisciplineModeWeighted adds up the weights of all matching rules per discipline
+	DisciplineModeWeighted = "weighted"
+
+	// DefaultDisciplineFallback is the discipline of issues not matching any rule
+	DefaultDisciplineFallback = "Other"
+)
+
+// Fields a discipline rule can match on. Any other value is taken as a custom field ID.
+const (
+	RuleFieldSummary    = "summary"
+	RuleFieldLabels     = "labels"
+	RuleFieldComponents = "components"
+	RuleFieldIssueType  = "issuetype"
+	RuleFieldEpic       = "epic"
+	RuleFieldAssignee   = "assignee"
+	RuleFieldDiscipline = "discipline"
+)
+
+// DisciplineRule assigns a discipline to the issues whose field matches the pattern
+type DisciplineRule struct {
+	Name    string `mapstructure:"name"`
+	Field   string `mapstructure:"field"`
+	Pattern string `mapstructure:"pattern"`
+	// Discipline can refer to groups of the pattern ("$1"), the matched value is used when empty
+	Discipline string  `mapstructure:"discipline"`
+	Weight     float64 `mapstructure:"weight"`
+}
+
+// DefaultDisciplineRules looks up the discipline in brackets in the summary
+// (e.g. "[Backend] Fix login"), then in the discipline custom field and
+// finally takes the first component
+func DefaultDisciplineRules() []DisciplineRule {
+	rules := []DisciplineRule{}
+	for _, d := range []string{"Backend", "Web", "AutoQA", "Android", "iOS"} {
+		rules = append(rules, DisciplineRule{
+			Name:       d + " in summary",
+			Field:      RuleFieldSummary,
+			Pattern:    fmt.Sprintf(`(?i)\[[^\]]*\b%s\b[^\]]*\]`, d),
+			Discipline: d,
+		})
+	}
+	return append(rules,
+		DisciplineRule{Name: "discipline field", Field: RuleFieldDiscipline, Pattern: `.+`},
+		DisciplineRule{Name: "first component", Field: RuleFieldComponents, Pattern: `.+`},
+	)
+}
+
+type compiledRule struct {
+	DisciplineRule
+	regex *regexp.Regexp
+}
+
+// DisciplineHelper classifies issues in disciplines following an ordered list of rules
+type DisciplineHelper struct {
+	rules    []compiledRule
+	mode     string
+	fallback string
+}
+
+// NewDisciplineHelper compiles the rules, the default ones are used when none are given
+func NewDisciplineHelper(rules []DisciplineRule, mode, fallback string) (DisciplineHelper, error) {
+
+	if len(rules) == 0 {
+		rules = DefaultDisciplineRules()
+	}
+	if mode == "" {
+		mode = DisciplineModeFirstMatch
+	}
+	if mode != DisciplineModeFirstMatch && mode != DisciplineModeWeighted {
+		return DisciplineHelper{}, errors.Errorf(
+			"unknown discipline mode %q, expected %s or %s", mode, DisciplineModeFirstMatch, DisciplineModeWeighted)
+	}
+	if fallback == "" {
+		fallback = DefaultDisciplineFallback
+	}
+
+	compiled := make([]compiledRule, len(rules))
+	for i, r := range rules {
+		if r.Field == "" {
+			return DisciplineHelper{}, errors.Errorf("discipline rule %d (%s) has no field", i+1, r.Name)
+		}
+		regex, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return DisciplineHelper{}, errors.Wrapf(err, "error compiling pattern of discipline rule %d (%s)", i+1, r.Name)
+		}
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("rule %d", i+1)
+		}
+		if r.Weight == 0 {
+			r.Weight = 1
+		}
+		r.Field = strings.ToLower(r.Field)
+		compiled[i] = compiledRule{DisciplineRule: r, regex: regex}
+	}
+
+	return DisciplineHelper{rules: compiled, mode: mode, fallback: fallback}, nil
+}
+
+// DisciplineSubject is an issue being classified. The details of the issue are
+// only loaded when a rule needs fields not included in the Sprint report.
+type DisciplineSubject struct {
+	Issue   jira.Issue
+	load    func() (*jira.SimpleIssue, error)
+	details *jira.SimpleIssue
+	err     error
+}
+
+// NewDisciplineSubject creates a subject from an issue of the Sprint report
+// and a function loading its details
+func NewDisciplineSubject(issue jira.Issue, load func() (*jira.SimpleIssue, error)) *DisciplineSubject {
+	return &DisciplineSubject{Issue: issue, load: load}
+}
+
+// Details loads the issue details once
+func (s *DisciplineSubject) Details() (*jira.SimpleIssue, error) {
+	if s.details == nil && s.err == nil {
+		s.details, s.err = s.load()
+	}
+	return s.details, s.err
+}
+
+// values returns the values of a field of the issue
+func (s *DisciplineSubject) values(field string) ([]string, error) {
+
+	switch field {
+	case RuleFieldSummary:
+		return []string{s.Issue.Summary}, nil
+	case RuleFieldIssueType:
+		return []string{s.Issue.TypeName}, nil
+	case RuleFieldAssignee:
+		if s.Issue.Assignee != "" {
+			return []string{s.Issue.Assignee}, nil
+		}
+	case RuleFieldEpic:
+		if s.Issue.Epic != "" {
+			return []string{s.Issue.Epic}, nil
+		}
+	}
+
+	d, err := s.Details()
+	if err != nil {
+		return nil, err
+	}
+
+	switch field {
+	case RuleFieldSummary, RuleFieldIssueType:
+		return nil, nil
+	case RuleFieldAssignee:
+		if d.Fields.Assignee == nil {
+			return nil, nil
+		}
+		return []string{d.Fields.Assignee.DisplayName}, nil
+	case RuleFieldEpic:
+		return nonEmpty(d.Fields.ParentKey), nil
+	case RuleFieldLabels:
+		return d.Fields.Labels, nil
+	case RuleFieldComponents:
+		names := make([]string, len(d.Fields.Components))
+		for i, c := range d.Fields.Components {
+			names[i] = c.Name
+		}
+		return names, nil
+	case RuleFieldDiscipline:
+		return nonEmpty(d.Fields.Discipline.Value), nil
+	default:
+		return rawFieldValues(d.Fields.Raw[field]), nil
+	}
+}
+
+// RuleEvaluation is the outcome of a single rule for an issue
+type RuleEvaluation struct {
+	Rule       DisciplineRule
+	Values     []string
+	Matched    bool
+	Discipline string
+	Err        error
+}
+
+// DisciplineResult is the discipline of an issue and how it was decided
+type DisciplineResult struct {
+	Discipline  string
+	Rule        string
+	Evaluations []RuleEvaluation
+	Scores      map[string]float64
+}
+
+// Classify returns the discipline of the issue. In first-match mode the rules
+// are evaluated until one matches, otherwise all of them are. The fallback
+// discipline is returned along with the error when the details can't be loaded.
+func (h DisciplineHelper) Classify(subject *DisciplineSubject) (DisciplineResult, error) {
+	return h.evaluate(subject, h.mode == DisciplineModeWeighted)
+}
+
+// errDetailsNeeded stops the classification when checking whether details are needed
+var errDetailsNeeded = errors.New("issue details needed")
+
+// NeedsDetails checks whether classifying the issue requires fetching its
+// details, i.e. no rule can decide with the Sprint report data alone
+func (h DisciplineHelper) NeedsDetails(issue jira.Issue) bool {
+	needed := false
+	subject := NewDisciplineSubject(issue, func() (*jira.SimpleIssue, error) {
+		needed = true
+		return nil, errDetailsNeeded
+	})
+	h.Classify(subject)
+	return needed
+}
+
+// Fields returns the JIRA fields the rules read from the issue details, the
+// epic and discipline are read from the custom fields of the mapping
+func (h DisciplineHelper) Fields(mapping jira.FieldMapping) []string {
+
+	var fields []string
+	seen := map[string]bool{}
+
+	for _, r := range h.rules {
+		field := r.Field
+		switch field {
+		case RuleFieldEpic:
+			field = mapping.EpicLink
+		case RuleFieldDiscipline:
+			field = mapping.Discipline
+		}
+		if field != "" && !seen[field] {
+			seen[field] = true
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
+
+// Explain evaluates all the rules for the issue, reporting which one decided
+// the discipline
+func (h DisciplineHelper) Explain(subject *DisciplineSubject) (DisciplineResult, error) {
+	return h.evaluate(subject, true)
+}
+
+func (h DisciplineHelper) evaluate(subject *DisciplineSubject, all bool) (DisciplineResult, error) {
+
+	result := DisciplineResult{Scores: map[string]float64{}}
+	var firstErr error
+	var order []string
+
+	for _, rule := range h.rules {
+		eval := rule.evaluate(subject)
+		result.Evaluations = append(result.Evaluations, eval)
+
+		if eval.Err != nil && firstErr == nil {
+			firstErr = eval.Err
+		}
+		if !eval.Matched {
+			continue
+		}
+
+		if result.Rule == "" && h.mode == DisciplineModeFirstMatch {
+			result.Discipline = eval.Discipline
+			result.Rule = rule.Name
+		}
+		if _, ok := result.Scores[eval.Discipline]; !ok {
+			order = append(order, eval.Discipline)
+		}
+		result.Scores[eval.Discipline] += rule.Weight
+
+		if !all {
+			break
+		}
+	}
+
+	if h.mode == DisciplineModeWeighted {
+		best := 0.0
+		for _, d := range order {
+			if result.Scores[d] > best {
+				best = result.Scores[d]
+				result.Discipline = d
+			}
+		}
+		if result.Discipline != "" {
+			result.Rule = fmt.Sprintf("highest score (%g)", best)
+		}
+	}
+
+	if result.Discipline == "" {
+		result.Discipline = h.fallback
+		result.Rule = "fallback"
+		return result, firstErr
+	}
+
+	return result, nil
+}
+
+// evaluate checks the rule against the values of its field
+func (r compiledRule) evaluate(subject *DisciplineSubject) RuleEvaluation {
+
+	eval := RuleEvaluation{Rule: r.DisciplineRule}
+
+	eval.Values, eval.Err = subject.values(r.Field)
+	if eval.Err != nil {
+		return eval
+	}
+
+	for _, value := range eval.Values {
+		match := r.regex.FindStringSubmatchIndex(value)
+		if match == nil {
+			continue
+		}
+		discipline := value
+		if r.Discipline != "" {
+			discipline = string(r.regex.ExpandString(nil, r.Discipline, value, match))
+		}
+		discipline = strings.TrimSpace(discipline)
+		if discipline == "" {
+			continue
+		}
+		eval.Matched = true
+		eval.Discipline = discipline
+		return eval
+	}
+
+	return eval
+}
+
+// rawFieldValues turns the value of a custom field into strings: plain values,
+// options ({"value": ...}), named objects ({"name": ...}) and lists of them
+func rawFieldValues(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var list []json.RawMessage
+	if err := json.Unmarshal(raw, &list); err == nil {
+		var values []string
+		for _, item := range list {
+			values = append(values, rawFieldValues(item)...)
+		}
+		return values
+	}
+
+	var object struct {
+		Value       string `json:"value"`
+		Name        string `json:"name"`
+		DisplayName string `json:"displayName"`
+		Key         string `json:"key"`
+	}
+	if err := json.Unmarshal(raw, &object); err == nil {
+		for _, v := range []string{object.Value, object.Name, object.DisplayName, object.Key} {
+			if v != "" {
+				return []string{v}
+			}
+		}
+		return nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil || value == nil {
+		return nil
+	}
+	return []string{fmt.Sprint(value)}
+}
+
+// nonEmpty returns a single value list, empty when the value is empty
+func nonEmpty(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return []string{value}
+}
@@ -0,0 +1,383 @@
+package helper
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jvalecillos/jira-metrics/pkg/googlesheets"
+	"github.com/jvalecillos/jira-metrics/pkg/jira"
+	"github.com/jvalecillos/jira-metrics/pkg/pool"
+	"github.com/pkg/errors"
+)
+
+const (
+	FlowToDo       = "todo"
+	FlowInProgress = "in-progress"
+	FlowDone       = "done"
+
+	// flowDateLayout is the format of the dates of the flow rows
+	flowDateLayout = "2006-01-02 15:04"
+)
+
+// jiraFlowCategories maps the keys of the Jira status categories to the flow ones
+var jiraFlowCategories = map[string]string{
+	"new":           FlowToDo,
+	"indeterminate": FlowInProgress,
+	"done":          FlowDone,
+}
+
+// FlowCategories tells the flow category of every status: to do, in
+// progress or done. The status category of Jira is used unless the status
+// name is mapped to another category.
+type FlowCategories struct {
+	byID     map[string]string
+	byName   map[string]string
+	override map[string]string
+}
+
+// NewFlowCategories creates the flow categories of the given statuses, the
+// overrides map status names (case insensitive) to flow categories
+func NewFlowCategories(statuses []jira.Status, overrides map[string]string) (FlowCategories, error) {
+
+	c := FlowCategories{
+		byID:     make(map[string]string, len(statuses)),
+		byName:   make(map[string]string, len(statuses)),
+		override: make(map[string]string, len(overrides)),
+	}
+
+	for _, s := range statuses {
+		category, ok := jiraFlowCategories[s.StatusCategory.Key]
+		if !ok {
+			category = FlowToDo
+		}
+		c.byID[s.ID] = category
+		c.byName[strings.ToLower(s.Name)] = category
+	}
+
+	for name, category := range overrides {
+		category = strings.ToLower(category)
+		switch category {
+		case FlowToDo, FlowInProgress, FlowDone:
+		default:
+			return FlowCategories{}, errors.Errorf(
+				"unknown flow category %q of status %q, expected %s, %s or %s",
+				category, name, FlowToDo, FlowInProgress, FlowDone,
+			)
+		}
+		c.override[strings.ToLower(name)] = category
+	}
+
+	return c, nil
+}
+
+// Category returns the flow category of a status, to do when unknown
+func (c FlowCategories) Category(statusID, statusName string) string {
+	name := strings.ToLower(statusName)
+	if category, ok := c.override[name]; ok {
+		return category
+	}
+	if category, ok := c.byID[statusID]; ok {
+		return category
+	}
+	if category, ok := c.byName[name]; ok {
+		return category
+	}
+	return FlowToDo
+}
+
+// StatusTime is the time an issue spent in a status
+type StatusTime struct {
+	Status   string  `json:"status"`
+	Category string  `json:"category"`
+	Days     float64 `json:"days"`
+}
+
+// FlowIssue describes how an issue flowed through the statuses of the board.
+// Times are in days, lead time goes from the creation to done and cycle time
+// from the first time in progress to done, both are nil while not done.
+type FlowIssue struct {
+	Key          string       `json:"key"`
+	Summary      string       `json:"summary"`
+	Type         string       `json:"type"`
+	Sprint       string       `json:"sprint"`
+	Status       string       `json:"status"`
+	Created      time.Time    `json:"created"`
+	Started      *time.Time   `json:"started,omitempty"`
+	Done         *time.Time   `json:"done,omitempty"`
+	LeadTime     *float64     `json:"leadTime"`
+	CycleTime    *float64     `json:"cycleTime"`
+	TimeInStatus []StatusTime `json:"timeInStatus"`
+}
+
+// FlowStats describes the lead or cycle times of the done issues
+type FlowStats struct {
+	Issues       int     `json:"issues"`
+	Average      float64 `json:"average"`
+	Median       float64 `json:"median"`
+	Percentile85 float64 `json:"percentile85"`
+}
+
+// FlowHelper computes the flow metrics of issues from their changelog
+type FlowHelper struct {
+	changelogs  *jira.IssueChangelogs
+	categories  FlowCategories
+	sprintNames SprintNameHelper
+	browseURL   string
+	concurrency int
+}
+
+func NewFlowHelper(
+	changelogs *jira.IssueChangelogs,
+	categories FlowCategories,
+	sprintNames SprintNameHelper,
+	browseURL string,
+	concurrency int,
+) FlowHelper {
+	return FlowHelper{
+		changelogs:  changelogs,
+		categories:  categories,
+		sprintNames: sprintNames,
+		browseURL:   browseURL,
+		concurrency: concurrency,
+	}
+}
+
+// ProcessReports computes the flow of every issue of the given reports, which
+// must be sorted from the oldest to the newest Sprint. Issues in several
+// Sprints are listed once, with the last Sprint they were part of.
+func (f FlowHelper) ProcessReports(ctx context.Context, reports []jira.ReportResponse, now time.Time) ([]FlowIssue, error) {
+
+	var keys []string
+	sprints := map[string]string{}
+
+	for _, report := range reports {
+		sprint := f.sprintNames.Simplify(report.Sprint.Name)
+		c := report.Contents
+		for _, issues := range [][]jira.Issue{
+			c.CompletedIssues,
+			c.IssuesNotCompletedInCurrentSprint,
+			c.PuntedIssues,
+			c.IssuesCompletedInAnotherSprint,
+		} {
+			for _, issue := range issues {
+				if _, ok := sprints[issue.Key]; !ok {
+					keys = append(keys, issue.Key)
+				}
+				sprints[issue.Key] = sprint
+			}
+		}
+	}
+
+	flows := make([]FlowIssue, len(keys))
+
+	err := pool.ForEach(len(keys), f.concurrency, func(i int) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		history, err := f.changelogs.Get(ctx, keys[i])
+		if err != nil {
+			return errors.Wrapf(err, "error getting changelog of %s", keys[i])
+		}
+		flows[i] = Flow(*history, f.categories, now)
+		flows[i].Sprint = sprints[keys[i]]
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return flows, nil
+}
+
+// Flow replays the status changes of an issue to compute its flow metrics,
+// time in status is counted until the issue got done or until now
+func Flow(history jira.IssueHistory, categories FlowCategories, now time.Time) FlowIssue {
+
+	fields := history.Fields
+	created := fields.Created.Time
+	matcher := jira.FieldMatcher("status", "status")
+
+	flow := FlowIssue{
+		Key:     history.Key,
+		Summary: fields.Summary,
+		Type:    fields.Issuetype.Name,
+		Status:  fields.Status.Name,
+		Created: created,
+	}
+
+	// statusInterval is a period of time spent in a status
+	type statusInterval struct {
+		id, name     string
+		since, until time.Time
+	}
+
+	current := statusInterval{
+		id:    history.Changelog.ValueAt(fields.Status.ID, created, matcher, false),
+		name:  history.Changelog.ValueAt(fields.Status.Name, created, matcher, true),
+		since: created,
+	}
+	category := categories.Category(current.id, current.name)
+
+	if category == FlowInProgress {
+		flow.Started = &created
+	}
+
+	var doneAt *time.Time
+	if category == FlowDone {
+		doneAt = &created
+	}
+
+	var intervals []statusInterval
+
+	for _, h := range history.Changelog.Sorted() {
+		for _, item := range h.Items {
+			if !matcher(item) {
+				continue
+			}
+			t := h.Created.Time
+
+			current.until = t
+			intervals = append(intervals, current)
+			current = statusInterval{id: item.To, name: item.ToString, since: t}
+
+			next := categories.Category(current.id, current.name)
+			if next == FlowInProgress && flow.Started == nil {
+				flow.Started = &t
+			}
+			// done is the last time the issue got done, if it still is
+			switch {
+			case next != FlowDone:
+				doneAt = nil
+			case category != FlowDone:
+				doneAt = &t
+			}
+			category = next
+		}
+	}
+
+	current.until = now
+	intervals = append(intervals, current)
+
+	end := now
+	if doneAt != nil {
+		end = *doneAt
+		flow.Done = doneAt
+		flow.LeadTime = optional(round(doneAt.Sub(created).Hours()/24, 2), true)
+		if flow.Started != nil && !flow.Started.After(*doneAt) {
+			flow.CycleTime = optional(round(doneAt.Sub(*flow.Started).Hours()/24, 2), true)
+		}
+	}
+
+	// index of every status in the time in status, in order of appearance
+	index := map[string]int{}
+
+	for _, i := range intervals {
+		if !i.since.Before(end) {
+			break
+		}
+		if i.until.After(end) {
+			i.until = end
+		}
+		n, ok := index[i.name]
+		if !ok {
+			n = len(flow.TimeInStatus)
+			index[i.name] = n
+			flow.TimeInStatus = append(flow.TimeInStatus, StatusTime{
+				Status:   i.name,
+				Category: categories.Category(i.id, i.name),
+			})
+		}
+		flow.TimeInStatus[n].Days += i.until.Sub(i.since).Hours() / 24
+	}
+
+	for i := range flow.TimeInStatus {
+		flow.TimeInStatus[i].Days = round(flow.TimeInStatus[i].Days, 2)
+	}
+
+	return flow
+}
+
+// FlowSummary returns the stats of the lead and cycle times of the done issues
+func FlowSummary(flows []FlowIssue) (lead FlowStats, cycle FlowStats) {
+
+	var leadTimes, cycleTimes []float64
+	for _, f := range flows {
+		if f.LeadTime != nil {
+			leadTimes = append(leadTimes, *f.LeadTime)
+		}
+		if f.CycleTime != nil {
+			cycleTimes = append(cycleTimes, *f.CycleTime)
+		}
+	}
+
+	return flowStats(leadTimes), flowStats(cycleTimes)
+}
+
+// flowStats describes a set of times in days
+func flowStats(times []float64) FlowStats {
+	if len(times) == 0 {
+		return FlowStats{}
+	}
+	sorted := append([]float64(nil), times...)
+	sort.Float64s(sorted)
+	return FlowStats{
+		Issues:       len(times),
+		Average:      round(mean(times), 2),
+		Median:       round(median(times), 2),
+		Percentile85: round(percentile(sorted, 0.85), 2),
+	}
+}
+
+// FlowRows generates the rows of the "Flow" table
+func (f FlowHelper) FlowRows(flows []FlowIssue) googlesheets.FlowRowArray {
+
+	rows := make(googlesheets.FlowRowArray, len(flows))
+
+	for i, flow := range flows {
+		rows[i] = googlesheets.FlowRow{
+			Sprint:       flow.Sprint,
+			TicketNumber: flow.Key,
+			Title:        flow.Summary,
+			Link:         jiraLink(f.browseURL, flow.Key, flow.Summary),
+			IssueType:    flow.Type,
+			Status:       flow.Status,
+			Created:      flow.Created.Local().Format(flowDateLayout),
+			Started:      formatFlowDate(flow.Started),
+			Done:         formatFlowDate(flow.Done),
+			LeadTime:     FormatDays(flow.LeadTime, ""),
+			CycleTime:    FormatDays(flow.CycleTime, ""),
+			TimeInStatus: flow.TimeInStatusSummary(),
+		}
+	}
+
+	return rows
+}
+
+// TimeInStatusSummary lists the days spent in each status as "Status: days"
+func (f FlowIssue) TimeInStatusSummary() string {
+	parts := make([]string, len(f.TimeInStatus))
+	for i, s := range f.TimeInStatus {
+		parts[i] = fmt.Sprintf("%s: %g", s.Status, s.Days)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatFlowDate formats an optional date, empty when missing
+func formatFlowDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Local().Format(flowDateLayout)
+}
+
+// FormatDays formats an optional number of days, missing when nil
+func FormatDays(days *float64, missing string) string {
+	if days == nil {
+		return missing
+	}
+	return fmt.Sprintf("%g", *days)
+}
@@ -0,0 +1,176 @@
+package helper
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jvalecillos/jira-metrics/pkg/jira"
+)
+
+// testFlowStatuses are the statuses of the board, by ID
+var testFlowStatuses = map[string]jira.Status{
+	"1": {ID: "1", Name: "To Do", StatusCategory: jira.StatusCategory{Key: "new"}},
+	"3": {ID: "3", Name: "In Progress", StatusCategory: jira.StatusCategory{Key: "indeterminate"}},
+	"5": {ID: "5", Name: "Done", StatusCategory: jira.StatusCategory{Key: "done"}},
+	"6": {ID: "6", Name: "Blocked", StatusCategory: jira.StatusCategory{Key: "indeterminate"}},
+}
+
+// flowTime returns the given UTC time of a day of October 2021
+func flowTime(day, hour int) time.Time {
+	return time.Date(2021, time.October, day, hour, 0, 0, 0, time.UTC)
+}
+
+// testIssueHistory builds an issue created at the given time which went
+// through the given statuses, changed a day apart at 9:00 from the 2nd
+func testIssueHistory(created time.Time, statuses ...string) jira.IssueHistory {
+
+	var histories []jira.ChangelogHistory
+	for i := 1; i < len(statuses); i++ {
+		from, to := testFlowStatuses[statuses[i-1]], testFlowStatuses[statuses[i]]
+		histories = append(histories, jira.ChangelogHistory{
+			Created: jira.Time{Time: flowTime(i+1, 9)},
+			Items: []jira.ChangelogItem{{
+				Field: "status", FieldID: "status",
+				From: from.ID, FromString: from.Name,
+				To: to.ID, ToString: to.Name,
+			}},
+		})
+	}
+
+	return jira.IssueHistory{
+		Key: "STR-1",
+		Fields: jira.IssueHistoryFields{
+			Status:  testFlowStatuses[statuses[len(statuses)-1]],
+			Created: jira.Time{Time: created},
+		},
+		Changelog: jira.Changelog{Total: len(histories), Histories: histories},
+	}
+}
+
+func TestFlow(t *testing.T) {
+
+	days := func(d float64) *float64 { return &d }
+	at := func(day, hour int) *time.Time {
+		t := flowTime(day, hour)
+		return &t
+	}
+
+	statuses := make([]jira.Status, 0, len(testFlowStatuses))
+	for _, s := range testFlowStatuses {
+		statuses = append(statuses, s)
+	}
+
+	now := flowTime(10, 9)
+
+	tests := []struct {
+		name         string
+		history      jira.IssueHistory
+		overrides    map[string]string
+		wantStarted  *time.Time
+		wantDone     *time.Time
+		wantLead     *float64
+		wantCycle    *float64
+		wantInStatus []StatusTime
+	}{
+		{
+			name:        "reopened and done again",
+			history:     testIssueHistory(flowTime(1, 9), "1", "3", "5", "3", "5"),
+			wantStarted: at(2, 9),
+			wantDone:    at(5, 9),
+			wantLead:    days(4),
+			wantCycle:   days(3),
+			wantInStatus: []StatusTime{
+				{Status: "To Do", Category: FlowToDo, Days: 1},
+				{Status: "In Progress", Category: FlowInProgress, Days: 2},
+				{Status: "Done", Category: FlowDone, Days: 1},
+			},
+		},
+		{
+			name:        "reopened",
+			history:     testIssueHistory(flowTime(1, 9), "1", "3", "5", "3"),
+			wantStarted: at(2, 9),
+			wantInStatus: []StatusTime{
+				{Status: "To Do", Category: FlowToDo, Days: 1},
+				{Status: "In Progress", Category: FlowInProgress, Days: 7},
+				{Status: "Done", Category: FlowDone, Days: 1},
+			},
+		},
+		{
+			name:        "created in progress",
+			history:     testIssueHistory(flowTime(1, 21), "3", "5"),
+			wantStarted: at(1, 21),
+			wantDone:    at(2, 9),
+			wantLead:    days(0.5),
+			wantCycle:   days(0.5),
+			wantInStatus: []StatusTime{
+				{Status: "In Progress", Category: FlowInProgress, Days: 0.5},
+			},
+		},
+		{
+			name:        "blocked is in progress",
+			history:     testIssueHistory(flowTime(1, 9), "1", "6", "3", "5"),
+			wantStarted: at(2, 9),
+			wantDone:    at(4, 9),
+			wantLead:    days(3),
+			wantCycle:   days(2),
+			wantInStatus: []StatusTime{
+				{Status: "To Do", Category: FlowToDo, Days: 1},
+				{Status: "Blocked", Category: FlowInProgress, Days: 1},
+				{Status: "In Progress", Category: FlowInProgress, Days: 1},
+			},
+		},
+		{
+			name:        "blocked overridden as to do",
+			history:     testIssueHistory(flowTime(1, 9), "1", "6", "3", "5"),
+			overrides:   map[string]string{"blocked": FlowToDo},
+			wantStarted: at(3, 9),
+			wantDone:    at(4, 9),
+			wantLead:    days(3),
+			wantCycle:   days(1),
+			wantInStatus: []StatusTime{
+				{Status: "To Do", Category: FlowToDo, Days: 1},
+				{Status: "Blocked", Category: FlowToDo, Days: 1},
+				{Status: "In Progress", Category: FlowInProgress, Days: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			categories, err := NewFlowCategories(statuses, tt.overrides)
+			if err != nil {
+				t.Fatalf("NewFlowCategories: %v", err)
+			}
+
+			got := Flow(tt.history, categories, now)
+
+			if !reflect.DeepEqual(got.Started, tt.wantStarted) || !reflect.DeepEqual(got.Done, tt.wantDone) {
+				t.Errorf("started, done = %v, %v, want %v, %v", got.Started, got.Done, tt.wantStarted, tt.wantDone)
+			}
+			if !reflect.DeepEqual(got.LeadTime, tt.wantLead) || !reflect.DeepEqual(got.CycleTime, tt.wantCycle) {
+				t.Errorf("lead, cycle time = %s, %s, want %s, %s",
+					FormatDays(got.LeadTime, "-"), FormatDays(got.CycleTime, "-"),
+					FormatDays(tt.wantLead, "-"), FormatDays(tt.wantCycle, "-"))
+			}
+			if !reflect.DeepEqual(got.TimeInStatus, tt.wantInStatus) {
+				t.Errorf("time in status = %v, want %v", got.TimeInStatus, tt.wantInStatus)
+			}
+		})
+	}
+}
+
+func TestNewFlowCategories(t *testing.T) {
+
+	categories, err := NewFlowCategories(nil, map[string]string{"Blocked": "TODO"})
+	if err != nil {
+		t.Fatalf("NewFlowCategories: %v", err)
+	}
+	if got := categories.Category("6", "BLOCKED"); got != FlowToDo {
+		t.Errorf("Category() of an overridden status = %q, want %q", got, FlowToDo)
+	}
+
+	if _, err := NewFlowCategories(nil, map[string]string{"Blocked": "waiting"}); err == nil {
+		t.Error("NewFlowCategories() with an unknown category didn't fail")
+	}
+}
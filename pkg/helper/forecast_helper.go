@@ -0,0 +1,142 @@
+package helper
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/jvalecillos/jira-metrics/pkg/jira"
+	"github.com/pkg/errors"
+)
+
+const (
+	ForecastPoints = MetricsPoints
+	ForecastIssues = MetricsIssues
+
+	// maxForecastSprints stops simulations which would never finish
+	maxForecastSprints = 1000
+)
+
+// ForecastConfidences are the confidence levels reported by the forecasts
+var ForecastConfidences = []float64{0.50, 0.85, 0.95}
+
+// ForecastResult is the outcome of a forecast at a confidence level. Capped
+// results didn't finish within maxForecastSprints, so the value is a lower
+// bound of the Sprints needed.
+type ForecastResult struct {
+	Confidence float64 `json:"confidence"`
+	Value      float64 `json:"value"`
+	Capped     bool    `json:"capped,omitempty"`
+}
+
+// Forecaster runs Monte Carlo simulations sampling the throughput of past
+// Sprints, either completed points or completed issues
+type Forecaster struct {
+	samples     []float64
+	simulations int
+	rng         *rand.Rand
+}
+
+// NewForecaster creates a forecaster from the throughput samples, the seed
+// makes the simulations reproducible
+func NewForecaster(samples []float64, simulations int, seed int64) (*Forecaster, error) {
+
+	if len(samples) == 0 {
+		return nil, errors.New("no Sprint to sample the throughput from")
+	}
+	if simulations < 1 {
+		return nil, errors.New("the number of simulations must be positive")
+	}
+
+	return &Forecaster{
+		samples:     samples,
+		simulations: simulations,
+		rng:         rand.New(rand.NewSource(seed)),
+	}, nil
+}
+
+// ThroughputSamples returns the completed points or issues of every report
+func ThroughputSamples(reports []jira.ReportResponse, unit string) ([]float64, error) {
+
+	measure, err := NewMeasure(unit)
+	if err != nil {
+		return nil, err
+	}
+
+	samples := make([]float64, len(reports))
+
+	for i, report := range reports {
+		samples[i] = SummarizeSprint(report, measure).Completed
+	}
+
+	return samples, nil
+}
+
+// SprintsToFinish forecasts how many Sprints it takes to complete the
+// remaining amount, the more confidence the more Sprints
+func (f *Forecaster) SprintsToFinish(remaining float64) ([]ForecastResult, error) {
+
+	var positive bool
+	for _, s := range f.samples {
+		positive = positive || s > 0
+	}
+	if !positive {
+		return nil, errors.New("nothing was completed in the sampled Sprints")
+	}
+
+	outcomes := make([]float64, f.simulations)
+
+	for i := range outcomes {
+		var done float64
+		sprints := 0
+		for done < remaining && sprints < maxForecastSprints {
+			done += f.sample()
+			sprints++
+		}
+		outcomes[i] = float64(sprints)
+		if done < remaining {
+			// unfinished simulations sort after all the finished ones
+			outcomes[i] = math.Inf(1)
+		}
+	}
+
+	sort.Float64s(outcomes)
+
+	results := make([]ForecastResult, len(ForecastConfidences))
+	for i, c := range ForecastConfidences {
+		results[i] = ForecastResult{Confidence: c, Value: percentile(outcomes, c)}
+		if math.IsInf(results[i].Value, 1) {
+			results[i] = ForecastResult{Confidence: c, Value: maxForecastSprints, Capped: true}
+		}
+	}
+
+	return results, nil
+}
+
+// DeliveredIn forecasts the amount completed within the given number of
+// Sprints, the more confidence the less amount
+func (f *Forecaster) DeliveredIn(sprints int) []ForecastResult {
+
+	outcomes := make([]float64, f.simulations)
+
+	for i := range outcomes {
+		for s := 0; s < sprints; s++ {
+			outcomes[i] += f.sample()
+		}
+	}
+
+	sort.Float64s(outcomes)
+
+	results := make([]ForecastResult, len(ForecastConfidences))
+	for i, c := range ForecastConfidences {
+		// the amount completed at least in the given share of simulations
+		results[i] = ForecastResult{Confidence: c, Value: percentile(outcomes, 1-c)}
+	}
+
+	return results
+}
+
+// sample picks the throughput of a random past Sprint
+func (f *Forecaster) sample() float64 {
+	return f.samples[f.rng.Intn(len(f.samples))]
+}
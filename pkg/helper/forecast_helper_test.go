@@ -0,0 +1,110 @@
+package helper
+
+import (
+	"reflect"
+	"testing"
+)
+
+// forecastSamples are the completed points of past Sprints used by the tests
+var forecastSamples = []float64{5, 8, 13, 0, 10}
+
+func newTestForecaster(t *testing.T, samples []float64, simulations int, seed int64) *Forecaster {
+	t.Helper()
+	f, err := NewForecaster(samples, simulations, seed)
+	if err != nil {
+		t.Fatalf("NewForecaster: %v", err)
+	}
+	return f
+}
+
+func TestSprintsToFinish(t *testing.T) {
+
+	f := newTestForecaster(t, forecastSamples, 1000, 42)
+
+	got, err := f.SprintsToFinish(50)
+	if err != nil {
+		t.Fatalf("SprintsToFinish: %v", err)
+	}
+
+	want := []ForecastResult{
+		{Confidence: 0.50, Value: 7},
+		{Confidence: 0.85, Value: 9},
+		{Confidence: 0.95, Value: 11},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SprintsToFinish(50) = %v, want %v", got, want)
+	}
+}
+
+func TestDeliveredIn(t *testing.T) {
+
+	f := newTestForecaster(t, forecastSamples, 1000, 42)
+
+	got := f.DeliveredIn(3)
+
+	want := []ForecastResult{
+		{Confidence: 0.50, Value: 23},
+		{Confidence: 0.85, Value: 13},
+		{Confidence: 0.95, Value: 10},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DeliveredIn(3) = %v, want %v", got, want)
+	}
+}
+
+func TestForecastIsReproducible(t *testing.T) {
+
+	first, err := newTestForecaster(t, forecastSamples, 500, 7).SprintsToFinish(80)
+	if err != nil {
+		t.Fatalf("SprintsToFinish: %v", err)
+	}
+	second, err := newTestForecaster(t, forecastSamples, 500, 7).SprintsToFinish(80)
+	if err != nil {
+		t.Fatalf("SprintsToFinish: %v", err)
+	}
+
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("same seed gave %v and %v", first, second)
+	}
+}
+
+func TestSprintsToFinishWithoutThroughput(t *testing.T) {
+
+	f := newTestForecaster(t, []float64{0, 0, 0}, 100, 1)
+
+	if _, err := f.SprintsToFinish(10); err == nil {
+		t.Error("SprintsToFinish with all-zero samples didn't fail")
+	}
+}
+
+func TestSprintsToFinishCapped(t *testing.T) {
+
+	// one point every ten Sprints on average, so most simulations need
+	// around 920 Sprints and the slowest ones don't finish
+	samples := []float64{0, 0, 0, 0, 0, 0, 0, 0, 0, 1}
+	f := newTestForecaster(t, samples, 1000, 3)
+
+	got, err := f.SprintsToFinish(92)
+	if err != nil {
+		t.Fatalf("SprintsToFinish: %v", err)
+	}
+
+	want := []ForecastResult{
+		{Confidence: 0.50, Value: 914},
+		{Confidence: 0.85, Value: maxForecastSprints, Capped: true},
+		{Confidence: 0.95, Value: maxForecastSprints, Capped: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SprintsToFinish(92) = %v, want %v", got, want)
+	}
+}
+
+func TestNewForecasterValidation(t *testing.T) {
+
+	if _, err := NewForecaster(nil, 100, 1); err == nil {
+		t.Error("NewForecaster without samples didn't fail")
+	}
+	if _, err := NewForecaster(forecastSamples, 0, 1); err == nil {
+		t.Error("NewForecaster without simulations didn't fail")
+	}
+}
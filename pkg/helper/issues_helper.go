@@ -4,121 +4,305 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"os"
 	"path"
-	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/jvalecillos/jira-metrics/pkg/googlesheets"
 	"github.com/jvalecillos/jira-metrics/pkg/jira"
+	"github.com/jvalecillos/jira-metrics/pkg/pool"
 )
 
 type IssuesHelper struct {
-	srv *jira.IssueDetails
+	srv              *jira.IssueDetails
+	sprintNames      SprintNameHelper
+	disciplineHelper DisciplineHelper
+	concurrency      int
+	search           *jira.Search
+	prefetched       map[string]*jira.SimpleIssue
+	measure          Measure
 }
 
-func NewIssuesHelper(srv *jira.IssueDetails) IssuesHelper {
-	return IssuesHelper{srv: srv}
+// prefetchBatchSize is the number of issue keys looked up per JQL query
+const prefetchBatchSize = 50
+
+func NewIssuesHelper(srv *jira.IssueDetails, sprintNames SprintNameHelper, disciplineHelper DisciplineHelper) IssuesHelper {
+	return IssuesHelper{srv: srv, sprintNames: sprintNames, disciplineHelper: disciplineHelper}
 }
 
-func (d IssuesHelper) ProcessReport(report jira.ReportResponse) (googlesheets.MySheetRowArray, error) {
+// WithConcurrency sets how many issues are processed in parallel, which
+// mostly matters when issue details need to be fetched
+func (d IssuesHelper) WithConcurrency(concurrency int) IssuesHelper {
+	d.concurrency = concurrency
+	return d
+}
 
-	var rowArray googlesheets.MySheetRowArray = make(
-		googlesheets.MySheetRowArray,
-		len(report.Contents.CompletedIssues)+
-			len(report.Contents.IssuesNotCompletedInCurrentSprint)+
-			len(report.Contents.PuntedIssues),
-	)
+// WithSearch enables fetching the details of the issues of a report in
+// batches with JQL queries instead of one request per issue
+func (d IssuesHelper) WithSearch(search *jira.Search) IssuesHelper {
+	d.search = search
+	return d
+}
+
+// WithMeasure sets how issues weigh in the rows, their story points by default
+func (d IssuesHelper) WithMeasure(measure Measure) IssuesHelper {
+	d.measure = measure
+	return d
+}
 
-	index := 0
+// categorizedIssue is an issue of the report along with its category
+type categorizedIssue struct {
+	issue    jira.Issue
+	added    bool
+	category string
+}
+
+// ProcessReport generates the rows of the issues in the report, stopping as
+// soon as the context is cancelled
+func (d IssuesHelper) ProcessReport(ctx context.Context, report jira.ReportResponse) (googlesheets.MySheetRowArray, error) {
+
+	var issues []categorizedIssue
+
+	// checking if the issue was added to the Sprint
+	categorize := func(list []jira.Issue, category string) {
+		for _, j := range list {
+			_, added := report.Contents.IssueKeysAddedDuringSprint[j.Key]
+			issues = append(issues, categorizedIssue{issue: j, added: added, category: category})
+		}
+	}
 
 	// fillup completed tickets
-	for _, j := range report.Contents.CompletedIssues {
+	categorize(report.Contents.CompletedIssues, issueCompleted)
+	// fillup not-completed tickets
+	categorize(report.Contents.IssuesNotCompletedInCurrentSprint, issueNotCompleted)
+	// fillup removed from the Sprint
+	categorize(report.Contents.PuntedIssues, issueRemoved)
+	// fillup completed in another Sprint
+	categorize(report.Contents.IssuesCompletedInAnotherSprint, issueCompletedInAnotherSprint)
+
+	prefetched, err := d.prefetch(ctx, issues)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if err != nil {
+		// issues not prefetched are fetched one by one, the warning goes to
+		// stderr so the results can be piped
+		fmt.Fprintf(os.Stderr, "Unable to prefetch issue details for %s: %v\n", report.Sprint.Name, err)
+	}
+	d.prefetched = prefetched
+
+	var rowArray googlesheets.MySheetRowArray = make(googlesheets.MySheetRowArray, len(issues))
+
+	// rows keep the order of the report whatever order they are generated in
+	err = pool.ForEach(len(issues), d.concurrency, func(index int) error {
+		ci := issues[index]
+		rowArray[index] = d.generateRow(ctx, ci.issue, ci.added, report.Sprint, ci.category)
+		// a cancelled lookup leaves the row with the fallback discipline
+		return ctx.Err()
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return rowArray, nil
+}
+
+// prefetch fetches in batches the details of the issues which can't be
+// classified with the report data alone and aren't cached yet, or were
+// updated in JIRA after they got cached
+func (d IssuesHelper) prefetch(ctx context.Context, issues []categorizedIssue) (map[string]*jira.SimpleIssue, error) {
+
+	prefetched := map[string]*jira.SimpleIssue{}
 
-		added := false
-		// checking if the issue was added to the Sprint
-		if _, ok := report.Contents.IssueKeysAddedDuringSprint[j.Key]; ok {
-			added = true
+	if d.search == nil {
+		return prefetched, nil
+	}
+
+	fields := d.prefetchFields()
+
+	var keys, cachedKeys []string
+	seen := map[string]bool{}
+
+	for _, ci := range issues {
+		key := ci.issue.Key
+		if seen[key] || !d.disciplineHelper.NeedsDetails(ci.issue) {
+			continue
+		}
+		seen[key] = true
+		if d.srv.Cached(key, fields...) {
+			cachedKeys = append(cachedKeys, key)
+		} else {
+			keys = append(keys, key)
 		}
+	}
 
-		rowArray[index] = d.generateRow(j, added, report.Sprint.Name, issueCompleted)
-		index++
+	stale, err := d.staleKeys(ctx, cachedKeys, fields)
+	if err != nil {
+		return prefetched, err
 	}
+	keys = append(keys, stale...)
 
-	// fillup not-completed tickets
-	for _, j := range report.Contents.IssuesNotCompletedInCurrentSprint {
+	batches := batchKeys(keys)
+
+	var mu sync.Mutex
+
+	err = pool.ForEach(len(batches), d.concurrency, func(index int) error {
+		payloads, err := d.search.GetRaw(
+			ctx,
+			jira.KeysJQL(batches[index]),
+			fields,
+		)
+		if err != nil {
+			return err
+		}
 
-		added := false
-		// checking if the issue was added to the Sprint
-		if _, ok := report.Contents.IssueKeysAddedDuringSprint[j.Key]; ok {
-			added = true
+		for _, payload := range payloads {
+			issue, err := d.srv.Store(payload, fields...)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			prefetched[issue.Key] = issue
+			mu.Unlock()
 		}
+		return nil
+	})
 
-		rowArray[index] = d.generateRow(j, added, report.Sprint.Name, issueNotCompleted)
-		index++
+	return prefetched, err
+}
+
+// prefetchFields are the fields requested when prefetching issues, the ones
+// read by the discipline rules and the custom fields of the mapping to keep
+// the batches small, or all of them when the rules read none
+func (d IssuesHelper) prefetchFields() []string {
+
+	ruleFields := d.disciplineHelper.Fields(d.srv.FieldMapping())
+	if len(ruleFields) == 0 {
+		return []string{jira.AllFields}
 	}
 
-	// fillup removed from the Sprint
-	for _, j := range report.Contents.PuntedIssues {
+	fields := []string{"updated"}
+	seen := map[string]bool{"updated": true}
+	for _, f := range append(ruleFields, d.srv.FieldMapping().IDs()...) {
+		if !seen[f] {
+			seen[f] = true
+			fields = append(fields, f)
+		}
+	}
+
+	return fields
+}
 
-		added := false
-		// checking if the issue was added to the Sprint
-		if _, ok := report.Contents.IssueKeysAddedDuringSprint[j.Key]; ok {
-			added = true
+// staleKeys looks up when the cached issues were last updated in JIRA and
+// returns the ones updated after they got cached
+func (d IssuesHelper) staleKeys(ctx context.Context, keys []string, fields []string) ([]string, error) {
+
+	batches := batchKeys(keys)
+
+	err := pool.ForEach(len(batches), d.concurrency, func(index int) error {
+		updated, err := d.search.GetUpdated(ctx, jira.KeysJQL(batches[index]))
+		if err != nil {
+			return err
+		}
+		for key, u := range updated {
+			d.srv.SetUpdated(key, u)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		rowArray[index] = d.generateRow(j, added, report.Sprint.Name, issueRemoved)
-		index++
+	var stale []string
+	for _, key := range keys {
+		if !d.srv.Cached(key, fields...) {
+			stale = append(stale, key)
+		}
 	}
 
-	return rowArray, nil
+	return stale, nil
+}
+
+// batchKeys splits issue keys in batches of prefetchBatchSize
+func batchKeys(keys []string) [][]string {
+	var batches [][]string
+	for len(keys) > 0 {
+		n := prefetchBatchSize
+		if n > len(keys) {
+			n = len(keys)
+		}
+		batches = append(batches, keys[:n])
+		keys = keys[n:]
+	}
+	return batches
 }
 
 const (
-	issueCompleted    = "completed"
-	issueNotCompleted = "notCompleted"
-	issueRemoved      = "removed"
+	issueCompleted                = "completed"
+	issueNotCompleted             = "notCompleted"
+	issueRemoved                  = "removed"
+	issueCompletedInAnotherSprint = "completedInAnotherSprint"
 )
 
 func (i IssuesHelper) generateRow(
+	ctx context.Context,
 	j jira.Issue,
 	added bool,
-	sprintName string,
+	sprint jira.Sprint,
 	issueCategory string,
 ) googlesheets.MySheetRow {
 	row := googlesheets.MySheetRow{
-		Sprint:       SimplifySprintName(sprintName),
+		Sprint:       i.sprintNames.Simplify(sprint.Name),
 		TicketNumber: j.Key,
 		Title:        j.Summary,
 		Link:         i.generateJiraLink(j.Key, j.Summary),
+		SprintID:     strconv.Itoa(sprint.ID),
+		Metrics:      i.measure.Mode(),
 	}
 
+	// original estimation, or 1 when counting issues
+	initial := i.measure.Initial(j)
+
 	// If the ticket was added after starting the Sprint
 	if added {
-		// original estimation
-		row.Added = int(j.EstimateStatistic.StatFieldValue.Value)
+		row.Added = initial
 	} else {
-		// original estimation
-		row.Commited = int(j.EstimateStatistic.StatFieldValue.Value)
+		row.Commited = initial
 	}
 
 	if issueCategory == issueCompleted {
 		// original estimation
-		row.Completed = int(j.EstimateStatistic.StatFieldValue.Value)
+		row.Completed = initial
 	}
 
 	if issueCategory == issueNotCompleted {
 		// new estimation
-		row.CarriedOver = int(j.CurrentEstimateStatistic.StatFieldValue.Value)
+		row.CarriedOver = i.measure.Current(j)
 	}
 
 	if issueCategory == issueRemoved {
 		// original estimation
-		row.Dropped = int(j.EstimateStatistic.StatFieldValue.Value)
+		row.Dropped = initial
+	}
+
+	if issueCategory == issueCompletedInAnotherSprint {
+		// original estimation
+		row.CompletedInAnotherSprint = initial
 	}
 
 	row.Adjusted = row.Commited - row.Dropped + row.Added
 
-	row.Dicipline, _ = i.solveDicipline(j)
+	row.CurrentEstimate = i.measure.Current(j)
+	row.Reestimated = i.measure.reestimation(j)
+
+	if Unestimated(j) {
+		row.Unestimated = 1
+	}
+
+	row.Dicipline, _ = i.solveDicipline(ctx, j)
 
 	return row
 }
@@ -127,8 +311,14 @@ const issueBrowseSuffix = "/browse/"
 
 // generateJiraLink generates a GoogleSheet HyperLink given the issueID and the title
 func (i IssuesHelper) generateJiraLink(issueID, issueTitle string) string {
+	return jiraLink(i.srv.BrowseURL(), issueID, issueTitle)
+}
+
+// jiraLink generates a GoogleSheet HyperLink to an issue of the JIRA instance
+// at the given browse URL
+func jiraLink(browseURL, issueID, issueTitle string) string {
 	// Parse endpoint prefix URL
-	u, _ := url.Parse(i.srv.EndpointPrefix)
+	u, _ := url.Parse(browseURL)
 	u.Path = path.Join(u.Path, issueBrowseSuffix, issueID)
 
 	return fmt.Sprintf(
@@ -138,69 +328,17 @@ func (i IssuesHelper) generateJiraLink(issueID, issueTitle string) string {
 	)
 }
 
-var sprintRegex = regexp.MustCompile(`(?:[A-Z]{2,3})\s+Sprint\s+(\d{4})-W?(\d{2})-W?(\d{2})`)
-
-// SimplifySprintName changes the Sprint name to YYYY-WNN-NN
-func SimplifySprintName(name string) string {
-	// no match
-	if !sprintRegex.MatchString(name) {
-		return name
-	}
-	matches := sprintRegex.FindStringSubmatch(name)
-	// no matches with the expected groups
-	if len(matches) != 4 {
-		return name
-	}
-	return fmt.Sprintf("%s-W%s-%s", matches[1], matches[2], matches[3])
-}
-
-// regexMap is a list of regular expressions for looking up the dicipline in the title
-var regexMap = map[string]*regexp.Regexp{
-	"Backend": regexp.MustCompile(`(?i).*\[\s*(?:[^Web|AutoQA|Android|iOS])?\s*(Backend).*\].*`),
-	"Web":     regexp.MustCompile(`(?i).*\[\s*(?:[^Backend|AutoQA|Android|iOS])?\s*(Web).*\].*`),
-	"AutoQA":  regexp.MustCompile(`(?i).*\[\s*(?:[^Backend|Web|Android|iOS])?\s*(AutoQA).*\].*`),
-	"Android": regexp.MustCompile(`(?i).*\[\s*(?:[^Backend|Web|AutoQA|iOS])?\s*(Android).*\].*`),
-	"iOS":     regexp.MustCompile(`(?i).*\[\s*(?:[^Backend|Web|AutoQA|Android])?\s*(iOS).*\].*`),
-}
-
-// disciplines caches disciplines for already checked issues
-var disciplines map[string]string = make(map[string]string)
-
-// solveDicipline tries to find the dicipline for a given issue
-func (i IssuesHelper) solveDicipline(issue jira.Issue) (string, error) {
+// solveDicipline finds the dicipline for a given issue following the discipline
+// rules, issue details are only fetched when needed and come from the issue cache
+func (i IssuesHelper) solveDicipline(ctx context.Context, issue jira.Issue) (string, error) {
 
-	// check in local cache
-	if d, ok := disciplines[issue.Key]; ok {
-		fmt.Printf("discipline %s found in cache for %s\n", d, issue.Key)
-		return d, nil
-	}
-
-	for key, reg := range regexMap {
-		if reg.MatchString(issue.Summary) {
-			// save discipline for next lookup
-			disciplines[issue.Key] = key
-			return key, nil
-		}
-	}
-
-	issueWithDetails, err := i.srv.Get(context.Background(), issue.Key)
-	if err != nil {
-		return "Other", err
-	}
-
-	if issueWithDetails.Fields.Discipline.Value != "" {
-		// extract discipline from custom field
-		// save discipline for next lookup
-		disciplines[issue.Key] = issueWithDetails.Fields.Discipline.Value
-		return issueWithDetails.Fields.Discipline.Value, nil
-	} else {
-		if len(issueWithDetails.Fields.Components) > 0 {
-			// extract discipline from first component
-			// save discipline for next lookup
-			disciplines[issue.Key] = issueWithDetails.Fields.Components[0].Name
-			return issueWithDetails.Fields.Components[0].Name, nil
+	subject := NewDisciplineSubject(issue, func() (*jira.SimpleIssue, error) {
+		if details, ok := i.prefetched[issue.Key]; ok {
+			return details, nil
 		}
-	}
+		return i.srv.Get(ctx, issue.Key)
+	})
 
-	return "Other", nil
+	result, err := i.disciplineHelper.Classify(subject)
+	return result.Discipline, err
 }
@@ -0,0 +1,115 @@
+package helper
+
+import (
+	"strings"
+
+	"github.com/jvalecillos/jira-metrics/pkg/jira"
+	"github.com/pkg/errors"
+)
+
+const (
+	// MetricsPoints measures issues by their story points
+	MetricsPoints = "points"
+	// MetricsIssues counts issues, for teams which don't estimate
+	MetricsIssues = "issues"
+)
+
+// Measure tells how much an issue weighs in the metrics: its estimate or 1
+// when counting issues
+type Measure struct {
+	mode string
+}
+
+// NewMeasure creates a measure for the given metrics mode, points by default
+func NewMeasure(mode string) (Measure, error) {
+	switch strings.ToLower(mode) {
+	case "", MetricsPoints:
+		return Measure{mode: MetricsPoints}, nil
+	case MetricsIssues:
+		return Measure{mode: MetricsIssues}, nil
+	}
+	return Measure{}, errors.Errorf("unknown metrics mode %q, expected %s or %s", mode, MetricsPoints, MetricsIssues)
+}
+
+// Mode is the metrics mode of the measure
+func (m Measure) Mode() string {
+	if m.mode == "" {
+		return MetricsPoints
+	}
+	return m.mode
+}
+
+// Initial is the weight of an issue when the Sprint started or when it was
+// added to the Sprint
+func (m Measure) Initial(issue jira.Issue) float64 {
+	if m.mode == MetricsIssues {
+		return 1
+	}
+	return issue.EstimateStatistic.StatFieldValue.Value
+}
+
+// Current is the weight of an issue at the end of the Sprint, or when it was
+// completed or removed
+func (m Measure) Current(issue jira.Issue) float64 {
+	if m.mode == MetricsIssues {
+		return 1
+	}
+	return issue.CurrentEstimateStatistic.StatFieldValue.Value
+}
+
+// ReportSums are the totals of the issues of a report by category
+type ReportSums struct {
+	CompletedInitial                float64
+	NotCompletedInitial             float64
+	NotCompletedCurrent             float64
+	PuntedInitial                   float64
+	CompletedInAnotherSprintInitial float64
+	// AllCurrent only sums completed and not completed issues, like JIRA
+	AllCurrent float64
+}
+
+// Scope is the total of all the issues of the report
+func (s ReportSums) Scope() float64 {
+	return s.CompletedInitial + s.NotCompletedInitial + s.PuntedInitial + s.CompletedInAnotherSprintInitial
+}
+
+// Sums returns the totals of a report, which are the estimate sums of the
+// report when measuring points and the number of issues when counting them
+func (m Measure) Sums(c jira.Contents) ReportSums {
+
+	if m.mode == MetricsIssues {
+		return ReportSums{
+			CompletedInitial:                float64(len(c.CompletedIssues)),
+			NotCompletedInitial:             float64(len(c.IssuesNotCompletedInCurrentSprint)),
+			NotCompletedCurrent:             float64(len(c.IssuesNotCompletedInCurrentSprint)),
+			PuntedInitial:                   float64(len(c.PuntedIssues)),
+			CompletedInAnotherSprintInitial: float64(len(c.IssuesCompletedInAnotherSprint)),
+			AllCurrent:                      float64(len(c.CompletedIssues) + len(c.IssuesNotCompletedInCurrentSprint)),
+		}
+	}
+
+	return ReportSums{
+		CompletedInitial:                c.CompletedIssuesInitialEstimateSum.Value,
+		NotCompletedInitial:             c.IssuesNotCompletedInitialEstimateSum.Value,
+		NotCompletedCurrent:             c.IssuesNotCompletedEstimateSum.Value,
+		PuntedInitial:                   c.PuntedIssuesInitialEstimateSum.Value,
+		CompletedInAnotherSprintInitial: c.IssuesCompletedInAnotherSprintInitialEstimateSum.Value,
+		AllCurrent:                      c.AllIssuesEstimateSum.Value,
+	}
+}
+
+// Unestimated is true when an issue had no estimate neither at the start nor
+// at the end of the Sprint, whatever the metrics mode. Issues estimated at 0
+// points are estimated.
+func Unestimated(issue jira.Issue) bool {
+	return !issue.EstimateStatistic.StatFieldValue.Estimated() &&
+		!issue.CurrentEstimateStatistic.StatFieldValue.Estimated()
+}
+
+// Reestimated is true when the estimate of an issue changed during the
+// Sprint, whatever the metrics mode
+func Reestimated(issue jira.Issue) bool {
+	initial := issue.EstimateStatistic.StatFieldValue
+	current := issue.CurrentEstimateStatistic.StatFieldValue
+	return initial.Value != current.Value || initial.Estimated() != current.Estimated()
+}
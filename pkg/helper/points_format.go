@@ -0,0 +1,81 @@
+package helper
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	RoundNearest  = "nearest"
+	RoundHalfEven = "half-even"
+	RoundUp       = "up"
+	RoundDown     = "down"
+
+	// DefaultPointsDecimals keeps half points and common fractions readable
+	DefaultPointsDecimals = 2
+)
+
+// PointsFormat rounds story points for display. Points are always computed
+// and written to the sinks with their full precision.
+type PointsFormat struct {
+	decimals int
+	mode     string
+}
+
+// FullPrecision formats points without rounding them
+var FullPrecision = PointsFormat{decimals: -1}
+
+// NewPointsFormat creates a format rounding to the given number of decimals
+// with the given mode, nearest by default
+func NewPointsFormat(decimals int, mode string) (PointsFormat, error) {
+
+	if decimals < 0 {
+		return PointsFormat{}, errors.Errorf("invalid number of decimals %d", decimals)
+	}
+
+	mode = strings.ToLower(mode)
+	switch mode {
+	case "":
+		mode = RoundNearest
+	case RoundNearest, RoundHalfEven, RoundUp, RoundDown:
+	default:
+		return PointsFormat{}, errors.Errorf(
+			"unknown rounding %q, expected %s, %s, %s or %s",
+			mode, RoundNearest, RoundHalfEven, RoundUp, RoundDown,
+		)
+	}
+
+	return PointsFormat{decimals: decimals, mode: mode}, nil
+}
+
+// Round rounds the points to the decimals of the format
+func (p PointsFormat) Round(points float64) float64 {
+
+	if p.decimals < 0 {
+		return points
+	}
+
+	scale := math.Pow(10, float64(p.decimals))
+	scaled := points * scale
+
+	switch p.mode {
+	case RoundHalfEven:
+		scaled = math.RoundToEven(scaled)
+	case RoundUp:
+		scaled = math.Ceil(scaled)
+	case RoundDown:
+		scaled = math.Floor(scaled)
+	default:
+		scaled = math.Round(scaled)
+	}
+
+	return scaled / scale
+}
+
+// Format rounds the points and prints them without trailing zeros
+func (p PointsFormat) Format(points float64) string {
+	return strconv.FormatFloat(p.Round(points), 'f', -1, 64)
+}
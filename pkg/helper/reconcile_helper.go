@@ -0,0 +1,103 @@
+package helper
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/jvalecillos/jira-metrics/pkg/googlesheets"
+	"github.com/jvalecillos/jira-metrics/pkg/jira"
+)
+
+// reconcileTolerance absorbs floating point errors when comparing sums, far
+// below the smallest estimate used in practice (e.g. half a point)
+const reconcileTolerance = 0.001
+
+// Mismatch is a total computed from the issue rows which doesn't match the
+// estimate sum of the JIRA report
+type Mismatch struct {
+	Metric   string  `json:"metric"`
+	Computed float64 `json:"computed"`
+	Jira     float64 `json:"jira"`
+}
+
+func (m Mismatch) String() string {
+	return fmt.Sprintf("%s: %g in the rows, %g in JIRA", m.Metric, m.Computed, m.Jira)
+}
+
+// Reconcile compares the totals of the rows generated by ProcessReport with
+// the sums of the report for the same measure. Every row is matched by its
+// ticket number with the category of the report it comes from, whatever the
+// order of the rows.
+func Reconcile(report jira.ReportResponse, rows googlesheets.MySheetRowArray, measure Measure) []Mismatch {
+
+	c := report.Contents
+	sums := measure.Sums(c)
+
+	lists := map[string][]jira.Issue{
+		issueCompleted:                c.CompletedIssues,
+		issueNotCompleted:             c.IssuesNotCompletedInCurrentSprint,
+		issueRemoved:                  c.PuntedIssues,
+		issueCompletedInAnotherSprint: c.IssuesCompletedInAnotherSprint,
+	}
+
+	categories := map[string]string{}
+	issues := 0
+	for category, list := range lists {
+		for _, j := range list {
+			categories[j.Key] = category
+		}
+		issues += len(list)
+	}
+
+	var mismatches []Mismatch
+
+	if len(rows) != issues {
+		mismatches = append(mismatches, Mismatch{Metric: "issues", Computed: float64(len(rows)), Jira: float64(issues)})
+	}
+
+	var scope, completed, carriedOver, dropped, completedInAnotherSprint, current, unknown float64
+
+	for _, row := range rows {
+		category, ok := categories[row.TicketNumber]
+		if !ok {
+			unknown++
+			continue
+		}
+
+		scope += row.Commited + row.Added
+
+		switch category {
+		case issueCompleted:
+			completed += row.Completed
+		case issueNotCompleted:
+			carriedOver += row.CarriedOver
+		case issueRemoved:
+			dropped += row.Dropped
+		case issueCompletedInAnotherSprint:
+			completedInAnotherSprint += row.CompletedInAnotherSprint
+		}
+
+		// the current estimates of JIRA only sum completed and not completed issues
+		if category == issueCompleted || category == issueNotCompleted {
+			current += row.CurrentEstimate
+		}
+	}
+
+	checks := []Mismatch{
+		{Metric: "issues not in the report", Computed: unknown},
+		{Metric: "scope", Computed: scope, Jira: sums.Scope()},
+		{Metric: "completed", Computed: completed, Jira: sums.CompletedInitial},
+		{Metric: "carried over", Computed: carriedOver, Jira: sums.NotCompletedCurrent},
+		{Metric: "dropped", Computed: dropped, Jira: sums.PuntedInitial},
+		{Metric: "completed in another Sprint", Computed: completedInAnotherSprint, Jira: sums.CompletedInAnotherSprintInitial},
+		{Metric: "all issues current estimate", Computed: current, Jira: sums.AllCurrent},
+	}
+
+	for _, check := range checks {
+		if math.Abs(check.Computed-check.Jira) > reconcileTolerance {
+			mismatches = append(mismatches, check)
+		}
+	}
+
+	return mismatches
+}
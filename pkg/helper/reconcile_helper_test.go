@@ -0,0 +1,167 @@
+package helper
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jvalecillos/jira-metrics/pkg/googlesheets"
+	"github.com/jvalecillos/jira-metrics/pkg/jira"
+)
+
+func sum(v float64) jira.EstimateSum {
+	return jira.EstimateSum{Value: v}
+}
+
+// testReconcileReport has an issue of every category, with sums matching
+// testReconcileRows
+func testReconcileReport() jira.ReportResponse {
+	return jira.ReportResponse{Contents: jira.Contents{
+		CompletedIssues:                   []jira.Issue{{Key: "STR-1"}},
+		IssuesNotCompletedInCurrentSprint: []jira.Issue{{Key: "STR-2"}},
+		PuntedIssues:                      []jira.Issue{{Key: "STR-3"}},
+		IssuesCompletedInAnotherSprint:    []jira.Issue{{Key: "STR-4"}},
+
+		CompletedIssuesInitialEstimateSum:                sum(5),
+		IssuesNotCompletedInitialEstimateSum:             sum(3),
+		IssuesNotCompletedEstimateSum:                    sum(8),
+		PuntedIssuesInitialEstimateSum:                   sum(2),
+		IssuesCompletedInAnotherSprintInitialEstimateSum: sum(1),
+		AllIssuesEstimateSum:                             sum(13),
+	}}
+}
+
+// testReconcileRows are the rows of testReconcileReport, not in the order
+// they are generated
+func testReconcileRows() googlesheets.MySheetRowArray {
+	return googlesheets.MySheetRowArray{
+		{TicketNumber: "STR-3", Commited: 2, Dropped: 2, CurrentEstimate: 2},
+		{TicketNumber: "STR-2", Added: 3, CarriedOver: 8, CurrentEstimate: 8},
+		{TicketNumber: "STR-4", Commited: 1, CompletedInAnotherSprint: 1, CurrentEstimate: 1},
+		{TicketNumber: "STR-1", Commited: 5, Completed: 5, CurrentEstimate: 5},
+	}
+}
+
+func TestReconcile(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		modify func(report *jira.ReportResponse, rows googlesheets.MySheetRowArray) googlesheets.MySheetRowArray
+		want   []Mismatch
+	}{
+		{
+			name: "matching totals in any order",
+		},
+		{
+			name: "completed points differ",
+			modify: func(report *jira.ReportResponse, rows googlesheets.MySheetRowArray) googlesheets.MySheetRowArray {
+				report.Contents.CompletedIssuesInitialEstimateSum = sum(6)
+				return rows
+			},
+			want: []Mismatch{
+				{Metric: "scope", Computed: 11, Jira: 12},
+				{Metric: "completed", Computed: 5, Jira: 6},
+			},
+		},
+		{
+			name: "missing row",
+			modify: func(report *jira.ReportResponse, rows googlesheets.MySheetRowArray) googlesheets.MySheetRowArray {
+				return rows[1:]
+			},
+			want: []Mismatch{
+				{Metric: "issues", Computed: 3, Jira: 4},
+				{Metric: "scope", Computed: 9, Jira: 11},
+				{Metric: "dropped", Computed: 0, Jira: 2},
+			},
+		},
+		{
+			name: "row of an issue not in the report",
+			modify: func(report *jira.ReportResponse, rows googlesheets.MySheetRowArray) googlesheets.MySheetRowArray {
+				return append(rows, googlesheets.MySheetRow{TicketNumber: "STR-9", Commited: 1})
+			},
+			want: []Mismatch{
+				{Metric: "issues", Computed: 5, Jira: 4},
+				{Metric: "issues not in the report", Computed: 1},
+			},
+		},
+		{
+			name: "current estimate of removed issues isn't summed",
+			modify: func(report *jira.ReportResponse, rows googlesheets.MySheetRowArray) googlesheets.MySheetRowArray {
+				rows[0].CurrentEstimate = 20
+				return rows
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, rows := testReconcileReport(), testReconcileRows()
+			if tt.modify != nil {
+				rows = tt.modify(&report, rows)
+			}
+
+			if got := Reconcile(report, rows, Measure{}); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Reconcile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileFractionalPoints(t *testing.T) {
+
+	report := jira.ReportResponse{Contents: jira.Contents{
+		CompletedIssues:                   []jira.Issue{{Key: "STR-1"}, {Key: "STR-2"}},
+		IssuesNotCompletedInCurrentSprint: []jira.Issue{{Key: "STR-3"}},
+
+		CompletedIssuesInitialEstimateSum:    sum(0.3),
+		IssuesNotCompletedInitialEstimateSum: sum(0.5),
+		IssuesNotCompletedEstimateSum:        sum(2.5),
+		AllIssuesEstimateSum:                 sum(2.8),
+	}}
+
+	// 0.1 + 0.2 isn't exactly 0.3 in floating point
+	rows := googlesheets.MySheetRowArray{
+		{TicketNumber: "STR-1", Commited: 0.1, Completed: 0.1, CurrentEstimate: 0.1},
+		{TicketNumber: "STR-2", Commited: 0.2, Completed: 0.2, CurrentEstimate: 0.2},
+		{TicketNumber: "STR-3", Commited: 0.5, CarriedOver: 2.5, CurrentEstimate: 2.5},
+	}
+
+	if got := Reconcile(report, rows, Measure{}); len(got) != 0 {
+		t.Errorf("Reconcile() = %v, want no mismatches", got)
+	}
+
+	// half a point is well above the tolerance
+	rows[2].CarriedOver = 2
+	want := []Mismatch{{Metric: "carried over", Computed: 2, Jira: 2.5}}
+	if got := Reconcile(report, rows, Measure{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Reconcile() = %v, want %v", got, want)
+	}
+}
+
+func TestReconcileIssueCount(t *testing.T) {
+
+	// each row weighs 1 in its category when counting issues
+	rows := googlesheets.MySheetRowArray{
+		{TicketNumber: "STR-3", Commited: 1, Dropped: 1, CurrentEstimate: 1},
+		{TicketNumber: "STR-2", Added: 1, CarriedOver: 1, CurrentEstimate: 1},
+		{TicketNumber: "STR-4", Commited: 1, CompletedInAnotherSprint: 1, CurrentEstimate: 1},
+		{TicketNumber: "STR-1", Commited: 1, Completed: 1, CurrentEstimate: 1},
+	}
+	measure := Measure{mode: MetricsIssues}
+
+	if got := Reconcile(testReconcileReport(), rows, measure); len(got) != 0 {
+		t.Errorf("Reconcile() = %v, want no mismatches", got)
+	}
+
+	// rows measured in points don't match the counts of the report
+	rows = testReconcileRows()
+	want := []Mismatch{
+		{Metric: "scope", Computed: 11, Jira: 4},
+		{Metric: "completed", Computed: 5, Jira: 1},
+		{Metric: "carried over", Computed: 8, Jira: 1},
+		{Metric: "dropped", Computed: 2, Jira: 1},
+		{Metric: "all issues current estimate", Computed: 13, Jira: 2},
+	}
+	if got := Reconcile(testReconcileReport(), rows, measure); !reflect.DeepEqual(got, want) {
+		t.Errorf("Reconcile() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,46 @@
+package helper
+
+import (
+	"context"
+
+	"github.com/jvalecillos/jira-metrics/pkg/jira"
+	"github.com/jvalecillos/jira-metrics/pkg/pool"
+	"github.com/pkg/errors"
+)
+
+// SprintRef identifies a Sprint of a board
+type SprintRef struct {
+	ID   string
+	Name string
+}
+
+// FetchReports gets the reports of the given Sprints fetching up to
+// concurrency of them in parallel. Reports keep the order of the Sprints.
+func FetchReports(
+	ctx context.Context,
+	getter jira.ReportGetter,
+	rapidViewId string,
+	sprints []SprintRef,
+	concurrency int,
+) ([]jira.ReportResponse, error) {
+
+	reports := make([]jira.ReportResponse, len(sprints))
+
+	err := pool.ForEach(len(sprints), concurrency, func(i int) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		report, err := getter.Get(ctx, rapidViewId, sprints[i].ID)
+		if err != nil {
+			return errors.Wrapf(err, "error getting report of Sprint %s", sprints[i].Name)
+		}
+		reports[i] = *report
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
@@ -2,6 +2,7 @@ package helper
 
 import (
 	"context"
+	"sort"
 
 	"github.com/jvalecillos/jira-metrics/pkg/googlesheets"
 	"google.golang.org/api/sheets/v4"
@@ -14,6 +15,8 @@ const (
 	valueInputOption = "USER_ENTERED"
 	// How the input data should be inserted.
 	insertDataOption = "INSERT_ROWS"
+	// How the values should be rendered when reading them back.
+	valueRenderOption = "FORMULA"
 )
 
 type SpreadSheetHelper struct {
@@ -89,3 +92,104 @@ func (s SpreadSheetHelper) ResetFormat(
 		Context(ctx).
 		Do()
 }
+
+// Get reads the values of the given spreadsheet range. Formulas are returned
+// as they were entered so rows can be compared against freshly generated ones.
+func (s SpreadSheetHelper) Get(
+	ctx context.Context,
+	spreadSheetID string,
+	readRange string,
+) (googlesheets.GoogleSheetValues, error) {
+
+	resp, err := s.srv.Spreadsheets.Values.Get(spreadSheetID, readRange).
+		MajorDimension(majorDimension).
+		ValueRenderOption(valueRenderOption).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Values, nil
+}
+
+// Update overwrites the given rows in place
+func (s SpreadSheetHelper) Update(
+	ctx context.Context,
+	spreadSheetID string,
+	sheetRange googlesheets.SheetRange,
+	updates []googlesheets.RowUpdate,
+) (*sheets.BatchUpdateValuesResponse, error) {
+
+	data := make([]*sheets.ValueRange, len(updates))
+	for i, u := range updates {
+		data[i] = &sheets.ValueRange{
+			MajorDimension: majorDimension,
+			Range:          sheetRange.RowRange(u.RowNumber),
+			Values:         [][]interface{}{u.Values},
+		}
+	}
+
+	rb := &sheets.BatchUpdateValuesRequest{
+		ValueInputOption: valueInputOption,
+		Data:             data,
+	}
+
+	return s.srv.Spreadsheets.Values.BatchUpdate(spreadSheetID, rb).
+		Context(ctx).
+		Do()
+}
+
+// DeleteRows removes the given rows (1-based row numbers) from the sheet
+func (s SpreadSheetHelper) DeleteRows(
+	ctx context.Context,
+	spreadSheetID string,
+	gid int64,
+	rowNumbers []int64,
+) (*sheets.BatchUpdateSpreadsheetResponse, error) {
+
+	requestBody := &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: deleteRowsRequests(gid, rowNumbers),
+	}
+
+	return s.srv.Spreadsheets.
+		BatchUpdate(spreadSheetID, requestBody).
+		Context(ctx).
+		Do()
+}
+
+// deleteRowsRequests returns the requests deleting the given rows, from the
+// bottom up so the remaining row numbers stay valid
+func deleteRowsRequests(gid int64, rowNumbers []int64) []*sheets.Request {
+
+	sorted := append([]int64(nil), rowNumbers...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] > sorted[j] })
+
+	requests := make([]*sheets.Request, len(sorted))
+	for i, rowNumber := range sorted {
+		requests[i] = &sheets.Request{
+			DeleteDimension: &sheets.DeleteDimensionRequest{
+				Range: &sheets.DimensionRange{
+					SheetId:    gid,
+					Dimension:  majorDimension,
+					StartIndex: rowNumber - 1,
+					EndIndex:   rowNumber,
+				},
+			},
+		}
+	}
+
+	return requests
+}
+
+// Clear removes the values of the given range keeping its format
+func (s SpreadSheetHelper) Clear(
+	ctx context.Context,
+	spreadSheetID string,
+	clearRange string,
+) (*sheets.ClearValuesResponse, error) {
+
+	return s.srv.Spreadsheets.Values.Clear(spreadSheetID, clearRange, &sheets.ClearValuesRequest{}).
+		Context(ctx).
+		Do()
+}
@@ -0,0 +1,104 @@
+package helper
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jvalecillos/jira-metrics/pkg/googlesheets"
+)
+
+// applyUpsert applies a plan to the rows of a sheet (index 0 is row 1) in
+// the order used by sync: updates, deletes and then appends
+func applyUpsert(sheet googlesheets.GoogleSheetValues, gid int64, plan googlesheets.UpsertPlan) googlesheets.GoogleSheetValues {
+
+	sheet = append(googlesheets.GoogleSheetValues(nil), sheet...)
+
+	for _, u := range plan.Updates {
+		sheet[u.RowNumber-1] = u.Values
+	}
+
+	for _, r := range deleteRowsRequests(gid, plan.Deletes) {
+		d := r.DeleteDimension.Range
+		if d.SheetId != gid || d.Dimension != majorDimension {
+			panic("unexpected delete request")
+		}
+		sheet = append(sheet[:d.StartIndex], sheet[d.EndIndex:]...)
+	}
+
+	return append(sheet, plan.Appends...)
+}
+
+func TestUpsertRowNumbers(t *testing.T) {
+
+	header := []interface{}{"Sprint", "Ticket", "Points"}
+
+	sheet := googlesheets.GoogleSheetValues{
+		header,
+		{"S1", "T-1", float64(3)},
+		{"S1", "T-2", float64(5)},
+		{"S2", "T-9", float64(2)},
+		{"S1", "T-3", float64(1)},
+		{"S1", "T-1", float64(3)},
+		{"S1", "T-4", float64(8)},
+	}
+	incoming := googlesheets.GoogleSheetValues{
+		{"S1", "T-1", 3},
+		{"S1", "T-3", 2},
+		{"S1", "T-4", 13},
+		{"S1", "T-5", 1},
+	}
+
+	sheetRange, err := googlesheets.ParseSheetRange("Tickets!A2:C")
+	if err != nil {
+		t.Fatalf("ParseSheetRange: %v", err)
+	}
+
+	plan := googlesheets.PlanUpsert(
+		sheetRange, sheet[1:], incoming,
+		googlesheets.ColumnKey(0, 1), nil, googlesheets.ColumnKey(0),
+	)
+
+	// the row numbers of the plan point at the rows of the existing range
+	for _, u := range plan.Updates {
+		if got := sheet[u.RowNumber-1][1]; got != u.Values[1] {
+			t.Errorf("update of row %d hits %v, want %v", u.RowNumber, got, u.Values[1])
+		}
+	}
+	wantDeletes := []int64{sheetRange.RowNumber(1), sheetRange.RowNumber(4)}
+	if !reflect.DeepEqual(plan.Deletes, wantDeletes) {
+		t.Errorf("Deletes = %v, want %v", plan.Deletes, wantDeletes)
+	}
+
+	got := applyUpsert(sheet, 7, plan)
+
+	want := googlesheets.GoogleSheetValues{
+		header,
+		{"S1", "T-1", float64(3)},
+		{"S2", "T-9", float64(2)},
+		{"S1", "T-3", 2},
+		{"S1", "T-4", 13},
+		{"S1", "T-5", 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sheet after upsert = %v, want %v", got, want)
+	}
+}
+
+func TestDeleteRowsRequests(t *testing.T) {
+
+	requests := deleteRowsRequests(7, []int64{3, 9, 5})
+
+	var got []int64
+	for _, r := range requests {
+		d := r.DeleteDimension.Range
+		if d.EndIndex != d.StartIndex+1 {
+			t.Errorf("request deletes rows [%d, %d), want a single row", d.StartIndex, d.EndIndex)
+		}
+		got = append(got, d.EndIndex)
+	}
+
+	// from the bottom up, as 1-based row numbers
+	if want := []int64{9, 5, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("deleted rows = %v, want %v", got, want)
+	}
+}
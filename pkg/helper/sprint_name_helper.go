@@ -0,0 +1,104 @@
+package helper
+
+import (
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultSprintNamePattern matches names like "STR Sprint 2021-W41-43"
+	DefaultSprintNamePattern = `(?:[A-Z]{2,3})\s+Sprint\s+(?P<year>\d{4})[-\s]?W?(?P<start>\d{2})-W?(?P<end>\d{2})`
+	// DefaultSprintLabelTemplate normalises Sprint names to YYYY-WNN-NN
+	DefaultSprintLabelTemplate = `${year}-W${start}-${end}`
+
+	// sprintYearGroup is the named group compared against the year filter
+	sprintYearGroup = "year"
+)
+
+type SprintNameHelper struct {
+	pattern       *regexp.Regexp
+	labelTemplate string
+	include       map[string]bool
+	exclude       map[string]bool
+}
+
+// NewSprintNameHelper creates a helper for filtering and normalising Sprint names.
+// The label template uses the named groups of the pattern (e.g. "${year}-W${start}"),
+// Sprints in the include list are always selected and the ones in the exclude list never.
+func NewSprintNameHelper(pattern, labelTemplate string, include, exclude []string) (SprintNameHelper, error) {
+
+	if pattern == "" {
+		pattern = DefaultSprintNamePattern
+	}
+	if labelTemplate == "" {
+		labelTemplate = DefaultSprintLabelTemplate
+	}
+
+	r, err := regexp.Compile(pattern)
+	if err != nil {
+		return SprintNameHelper{}, errors.Wrap(err, "error compiling Sprint name pattern")
+	}
+
+	return SprintNameHelper{
+		pattern:       r,
+		labelTemplate: labelTemplate,
+		include:       toSet(include),
+		exclude:       toSet(exclude),
+	}, nil
+}
+
+// Match checks whether a Sprint name is relevant for the given year. The year
+// is only compared when the pattern has a "year" named group and it's not empty.
+func (s SprintNameHelper) Match(name, year string) bool {
+
+	if s.exclude[name] {
+		return false
+	}
+
+	if s.include[name] {
+		return true
+	}
+
+	matches := s.pattern.FindStringSubmatch(name)
+	if matches == nil {
+		return false
+	}
+
+	if year == "" {
+		return true
+	}
+
+	if i := s.pattern.SubexpIndex(sprintYearGroup); i >= 0 {
+		return matches[i] == year
+	}
+
+	return true
+}
+
+// Excluded checks whether a Sprint name is in the exclude list
+func (s SprintNameHelper) Excluded(name string) bool {
+	return s.exclude[name]
+}
+
+// Simplify changes the Sprint name to the configured label, names not
+// matching the pattern are returned as they are
+func (s SprintNameHelper) Simplify(name string) string {
+
+	submatches := s.pattern.FindStringSubmatchIndex(name)
+	// no match
+	if submatches == nil {
+		return name
+	}
+
+	return string(s.pattern.ExpandString(nil, s.labelTemplate, name, submatches))
+}
+
+// toSet converts a list of strings into a lookup set
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
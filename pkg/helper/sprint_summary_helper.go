@@ -0,0 +1,181 @@
+package helper
+
+import (
+	"strconv"
+
+	"github.com/jvalecillos/jira-metrics/pkg/googlesheets"
+	"github.com/jvalecillos/jira-metrics/pkg/jira"
+)
+
+// SprintSummary aggregates the points and issues of a Sprint report. Points
+// follow the same estimates as the issue rows: the original estimate for
+// committed, added, dropped and completed issues and the current estimate for
+// carried over ones. Re-estimations compare the original and the current
+// estimate of every issue of the report. When counting issues points are the
+// number of issues instead, issues are still counted as re-estimated but the
+// points inflated and deflated are 0.
+type SprintSummary struct {
+	Committed   float64 `json:"committed"`
+	Added       float64 `json:"added"`
+	Dropped     float64 `json:"dropped"`
+	Adjusted    float64 `json:"adjusted"`
+	Completed   float64 `json:"completed"`
+	CarriedOver float64 `json:"carriedOver"`
+
+	CompletedIssues    int `json:"completedIssues"`
+	NotCompletedIssues int `json:"notCompletedIssues"`
+	DroppedIssues      int `json:"droppedIssues"`
+	AddedIssues        int `json:"addedIssues"`
+
+	// Inflated and Deflated are the points added and removed by re-estimating
+	// issues during the Sprint, both positive
+	Inflated          float64 `json:"inflated"`
+	Deflated          float64 `json:"deflated"`
+	ReestimatedIssues int     `json:"reestimatedIssues"`
+	TotalIssues       int     `json:"totalIssues"`
+	UnestimatedIssues int     `json:"unestimatedIssues"`
+
+	// CompletedInAnotherSprint are the points of the issues of the Sprint
+	// completed in another Sprint, neither completed nor carried over here
+	CompletedInAnotherSprint       float64 `json:"completedInAnotherSprint"`
+	CompletedInAnotherSprintIssues int     `json:"completedInAnotherSprintIssues"`
+}
+
+// SummarizeSprint computes the summary of a Sprint from the sums of the
+// report, only the points added during the Sprint are summed per issue
+func SummarizeSprint(report jira.ReportResponse, measure Measure) SprintSummary {
+
+	c := report.Contents
+	sums := measure.Sums(c)
+
+	summary := SprintSummary{
+		Completed:          sums.CompletedInitial,
+		CarriedOver:        sums.NotCompletedCurrent,
+		Dropped:            sums.PuntedInitial,
+		CompletedIssues:    len(c.CompletedIssues),
+		NotCompletedIssues: len(c.IssuesNotCompletedInCurrentSprint),
+		DroppedIssues:      len(c.PuntedIssues),
+
+		CompletedInAnotherSprint:       sums.CompletedInAnotherSprintInitial,
+		CompletedInAnotherSprintIssues: len(c.IssuesCompletedInAnotherSprint),
+	}
+
+	scope := sums.Scope()
+
+	for _, list := range [][]jira.Issue{
+		c.CompletedIssues,
+		c.IssuesNotCompletedInCurrentSprint,
+		c.PuntedIssues,
+		c.IssuesCompletedInAnotherSprint,
+	} {
+		for _, issue := range list {
+			if _, added := c.IssueKeysAddedDuringSprint[issue.Key]; added {
+				summary.Added += measure.Initial(issue)
+				summary.AddedIssues++
+			}
+
+			summary.TotalIssues++
+			if Unestimated(issue) {
+				summary.UnestimatedIssues++
+			}
+
+			if Reestimated(issue) {
+				summary.ReestimatedIssues++
+			}
+
+			delta := measure.reestimation(issue)
+			if delta > 0 {
+				summary.Inflated += delta
+			} else {
+				summary.Deflated -= delta
+			}
+		}
+	}
+
+	summary.Committed = scope - summary.Added
+	summary.Adjusted = summary.Committed - summary.Dropped + summary.Added
+
+	return summary
+}
+
+// SayDo is the ratio of completed to committed points
+func (s SprintSummary) SayDo() (float64, bool) {
+	return ratio(s.Completed, s.Committed)
+}
+
+// ScopeChange is the net change of the committed points during the Sprint,
+// as a ratio of the committed points
+func (s SprintSummary) ScopeChange() (float64, bool) {
+	return ratio(s.Adjusted-s.Committed, s.Committed)
+}
+
+// CompletionRate is the ratio of completed points to the adjusted scope
+func (s SprintSummary) CompletionRate() (float64, bool) {
+	return ratio(s.Completed, s.Adjusted)
+}
+
+// ReestimatedShare is the ratio of re-estimated issues to all the issues
+func (s SprintSummary) ReestimatedShare() (float64, bool) {
+	return ratio(float64(s.ReestimatedIssues), float64(s.TotalIssues))
+}
+
+// reestimation is the change of the estimate of an issue during the Sprint in
+// points, always 0 when counting issues
+func (m Measure) reestimation(issue jira.Issue) float64 {
+	if m.mode == MetricsIssues {
+		return 0
+	}
+	return issue.CurrentEstimateStatistic.StatFieldValue.Value - issue.EstimateStatistic.StatFieldValue.Value
+}
+
+// ratio divides a by b, it's undefined when b is zero
+func ratio(a, b float64) (float64, bool) {
+	if b == 0 {
+		return 0, false
+	}
+	return a / b, true
+}
+
+// SprintRow generates the row of the Sprint list of a report
+func SprintRow(report jira.ReportResponse, sprintNames SprintNameHelper, measure Measure) googlesheets.SprintRow {
+
+	summary := SummarizeSprint(report, measure)
+
+	return googlesheets.SprintRow{
+		Name:               report.Sprint.Name,
+		SprintID:           strconv.Itoa(report.Sprint.ID),
+		Sprint:             sprintNames.Simplify(report.Sprint.Name),
+		Commited:           summary.Committed,
+		Added:              summary.Added,
+		Dropped:            summary.Dropped,
+		Adjusted:           summary.Adjusted,
+		Completed:          summary.Completed,
+		CarriedOver:        summary.CarriedOver,
+		SayDo:              formatRatio(summary.SayDo()),
+		ScopeChange:        formatRatio(summary.ScopeChange()),
+		CompletionRate:     formatRatio(summary.CompletionRate()),
+		CompletedIssues:    summary.CompletedIssues,
+		NotCompletedIssues: summary.NotCompletedIssues,
+		DroppedIssues:      summary.DroppedIssues,
+		AddedIssues:        summary.AddedIssues,
+		Inflated:           summary.Inflated,
+		Deflated:           summary.Deflated,
+		ReestimatedIssues:  summary.ReestimatedIssues,
+		ReestimatedShare:   formatRatio(summary.ReestimatedShare()),
+
+		CompletedInAnotherSprint:       summary.CompletedInAnotherSprint,
+		CompletedInAnotherSprintIssues: summary.CompletedInAnotherSprintIssues,
+
+		UnestimatedIssues: summary.UnestimatedIssues,
+		Metrics:           measure.Mode(),
+	}
+}
+
+// formatRatio writes a ratio rounded to 4 decimals, empty when undefined, so
+// it reads back from the sheet as the same value
+func formatRatio(value float64, ok bool) string {
+	if !ok {
+		return ""
+	}
+	return strconv.FormatFloat(round(value, 4), 'f', -1, 64)
+}
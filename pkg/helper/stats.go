@@ -0,0 +1,68 @@
+package helper
+
+import (
+	"math"
+	"sort"
+)
+
+// mean is the arithmetic mean of the values, 0 without values
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// median is the middle value of the sorted values, or the mean of the two
+// middle ones, 0 without values
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+// stdDev is the population standard deviation of the values
+func stdDev(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	m := mean(values)
+	var sum float64
+	for _, v := range values {
+		sum += (v - m) * (v - m)
+	}
+	return math.Sqrt(sum / float64(len(values)))
+}
+
+// round rounds a value to the given number of decimals
+func round(value float64, decimals int) float64 {
+	p := math.Pow(10, float64(decimals))
+	return math.Round(value*p) / p
+}
+
+// percentile returns the value below which the given fraction of the sorted
+// values fall, using the nearest rank
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
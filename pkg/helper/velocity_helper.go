@@ -0,0 +1,147 @@
+package helper
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/jvalecillos/jira-metrics/pkg/googlesheets"
+	"github.com/jvalecillos/jira-metrics/pkg/jira"
+)
+
+// VelocityStats describes the completed points of a set of Sprints
+type VelocityStats struct {
+	Sprints int     `json:"sprints"`
+	Average float64 `json:"average"`
+	Median  float64 `json:"median"`
+	StdDev  float64 `json:"stdDev"`
+	// CV is the coefficient of variation, the standard deviation relative to
+	// the average, nil when nothing was completed
+	CV *float64 `json:"cv"`
+	// Predictability is 1 minus the mean distance of the say/do ratios to 1,
+	// so it's 1 when every commitment was met exactly and decreases both when
+	// completing less and more than committed. It's nil when nothing was
+	// committed in any Sprint.
+	Predictability *float64 `json:"predictability"`
+}
+
+// VelocityPoint is a Sprint of the velocity series along with the stats of
+// the rolling window ending with it
+type VelocityPoint struct {
+	Sprint    string        `json:"sprint"`
+	SprintID  int           `json:"sprintId"`
+	Committed float64       `json:"committed"`
+	Completed float64       `json:"completed"`
+	SayDo     *float64      `json:"sayDo"`
+	Rolling   VelocityStats `json:"rolling"`
+	// Metrics is the unit of the point: points, or issues when counting them
+	Metrics string `json:"metrics"`
+}
+
+// Velocity computes the velocity series of the given reports, which must be
+// sorted from the oldest to the newest Sprint, with rolling stats over the
+// last window Sprints. The stats of all the Sprints are returned as well.
+func Velocity(
+	reports []jira.ReportResponse,
+	sprintNames SprintNameHelper,
+	measure Measure,
+	window int,
+) ([]VelocityPoint, VelocityStats) {
+
+	if window < 1 {
+		window = 1
+	}
+
+	summaries := make([]SprintSummary, len(reports))
+	points := make([]VelocityPoint, len(reports))
+
+	for i, report := range reports {
+		summaries[i] = SummarizeSprint(report, measure)
+
+		start := i + 1 - window
+		if start < 0 {
+			start = 0
+		}
+
+		points[i] = VelocityPoint{
+			Sprint:    sprintNames.Simplify(report.Sprint.Name),
+			SprintID:  report.Sprint.ID,
+			Committed: summaries[i].Committed,
+			Completed: summaries[i].Completed,
+			SayDo:     optional(summaries[i].SayDo()),
+			Rolling:   velocityStats(summaries[start : i+1]),
+			Metrics:   measure.Mode(),
+		}
+	}
+
+	return points, velocityStats(summaries)
+}
+
+// velocityStats computes the stats of the completed points of the Sprints
+func velocityStats(summaries []SprintSummary) VelocityStats {
+
+	completed := make([]float64, len(summaries))
+	var distances []float64
+
+	for i, s := range summaries {
+		completed[i] = s.Completed
+		if sayDo, ok := s.SayDo(); ok {
+			distances = append(distances, math.Abs(1-sayDo))
+		}
+	}
+
+	stats := VelocityStats{
+		Sprints: len(summaries),
+		Average: mean(completed),
+		Median:  median(completed),
+		StdDev:  stdDev(completed),
+		CV:      optional(ratio(stdDev(completed), mean(completed))),
+	}
+
+	if len(distances) > 0 {
+		stats.Predictability = optional(math.Max(0, 1-mean(distances)), true)
+	}
+
+	return stats
+}
+
+// optional returns a pointer to the value when it's defined
+func optional(value float64, ok bool) *float64 {
+	if !ok {
+		return nil
+	}
+	return &value
+}
+
+// VelocityRows generates the rows of the velocity series
+func VelocityRows(points []VelocityPoint) googlesheets.VelocityRowArray {
+
+	rows := make(googlesheets.VelocityRowArray, len(points))
+
+	for i, p := range points {
+		rows[i] = googlesheets.VelocityRow{
+			Sprint:         p.Sprint,
+			SprintID:       strconv.Itoa(p.SprintID),
+			Commited:       p.Committed,
+			Completed:      p.Completed,
+			SayDo:          FormatOptional(p.SayDo),
+			Window:         p.Rolling.Sprints,
+			RollingAverage: p.Rolling.Average,
+			RollingMedian:  p.Rolling.Median,
+			StdDev:         p.Rolling.StdDev,
+			CV:             FormatOptional(p.Rolling.CV),
+			Predictability: FormatOptional(p.Rolling.Predictability),
+			Metrics:        p.Metrics,
+		}
+	}
+
+	return rows
+}
+
+// FormatOptional formats an optional ratio like the sheet rows, empty when
+// it's undefined
+func FormatOptional(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return formatRatio(*value, true)
+}
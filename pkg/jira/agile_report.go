@@ -0,0 +1,463 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jvalecillos/jira-metrics/pkg/pool"
+	"github.com/pkg/errors"
+)
+
+const (
+	// StatusSuffix used for listing all issue statuses
+	StatusSuffix = "/rest/api/2/status"
+
+	// agilePageSize is the number of issues requested per page
+	agilePageSize = 100
+	// agileDateLayout is the date format accepted by JQL
+	agileDateLayout = "2006-01-02 15:04"
+	// doneStatusCategory is the key of the status category of finished issues
+	doneStatusCategory = "done"
+)
+
+type agileUser struct {
+	DisplayName string `json:"displayName"`
+	AccountID   string `json:"accountId"`
+	Name        string `json:"name"`
+	Key         string `json:"key"`
+}
+
+type agileNamed struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type agileProject struct {
+	ID  string `json:"id"`
+	Key string `json:"key"`
+}
+
+type agileEpic struct {
+	ID   int    `json:"id"`
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+type agileIssueFields struct {
+	Summary    string       `json:"summary"`
+	IssueType  agileNamed   `json:"issuetype"`
+	Priority   *agileNamed  `json:"priority"`
+	Status     Status       `json:"status"`
+	Assignee   *agileUser   `json:"assignee"`
+	Project    agileProject `json:"project"`
+	Epic       *agileEpic   `json:"epic"`
+	Created    Time         `json:"created"`
+	Resolution *agileNamed  `json:"resolution"`
+}
+
+type agileIssue struct {
+	ID        string                     `json:"id"`
+	Key       string                     `json:"key"`
+	RawFields map[string]json.RawMessage `json:"fields"`
+	Changelog Changelog                  `json:"changelog"`
+}
+
+type agileIssuesResponse struct {
+	StartAt    int          `json:"startAt"`
+	MaxResults int          `json:"maxResults"`
+	Total      int          `json:"total"`
+	Issues     []agileIssue `json:"issues"`
+}
+
+// ReportGetter fetches the Sprint report of a board
+type ReportGetter interface {
+	Get(ctx context.Context, rapidViewId string, sprintId string) (*ReportResponse, error)
+}
+
+// AgileReport reconstructs the Sprint report using the official Jira Agile
+// REST API instead of the private Greenhopper endpoints. The issues in the
+// Sprint and the ones of the board updated since its start are fetched with
+// their changelog, which is replayed to find out the Sprint membership,
+// status and estimate of each issue at the start and the end of the Sprint.
+type AgileReport struct {
+	*Jira
+	estimateField string
+
+	statusMu         sync.Mutex
+	statusCategories map[string]string
+}
+
+// AgileReport wraps the Agile API based reporting, estimates are taken from
+// the story points field of the field mapping
+func (a *Jira) AgileReport() (*AgileReport, error) {
+	return &AgileReport{Jira: a, estimateField: a.fields.StoryPoints}, nil
+}
+
+// Get reconstructs the Sprint report for the given board and Sprint
+func (a *AgileReport) Get(ctx context.Context, rapidViewId string, sprintId string) (*ReportResponse, error) {
+
+	detailsSrv, _ := a.SprintDetails()
+
+	sprint, err := detailsSrv.Get(ctx, sprintId)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting Sprint details")
+	}
+
+	if sprint.StartDate == nil {
+		return nil, errors.Errorf("Sprint %s has not been started", sprint.Name)
+	}
+
+	if err := a.loadStatusCategories(ctx); err != nil {
+		return nil, errors.Wrap(err, "error getting issue statuses")
+	}
+
+	fields := []string{
+		"summary", "issuetype", "priority", "status", "assignee",
+		"project", "epic", "created", "resolution", a.estimateField,
+	}
+
+	// issues currently in the Sprint
+	sprintIssues, err := a.searchIssues(ctx, path.Join(SprintDetailsSuffix, sprintId, "issue"), "", fields)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting Sprint issues")
+	}
+
+	// issues which may have been removed from the Sprint
+	jql := fmt.Sprintf(
+		`updated >= "%s" AND (sprint is EMPTY OR sprint != %s)`,
+		sprint.StartDate.Format(agileDateLayout), sprintId,
+	)
+	boardIssues, err := a.searchIssues(ctx, path.Join(BoardSuffix, rapidViewId, "issue"), jql, fields)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting board issues")
+	}
+
+	report := &ReportResponse{
+		Sprint: Sprint{
+			ID:              sprint.ID,
+			Name:            sprint.Name,
+			State:           strings.ToUpper(sprint.State),
+			Goal:            sprint.Goal,
+			StartDate:       formatOptionalTime(sprint.StartDate),
+			EndDate:         formatOptionalTime(sprint.EndDate),
+			CompleteDate:    formatOptionalTime(sprint.CompleteDate),
+			IsoStartDate:    formatOptionalTime(sprint.StartDate),
+			IsoEndDate:      formatOptionalTime(sprint.EndDate),
+			IsoCompleteDate: formatOptionalTime(sprint.CompleteDate),
+		},
+		Contents: Contents{
+			IssueKeysAddedDuringSprint: IssuesAdded{},
+		},
+	}
+
+	end := time.Now()
+	if closed := sprint.ClosingDate(); closed != nil && closed.Before(end) {
+		end = *closed
+	}
+
+	for _, issue := range sprintIssues {
+		if err := a.classify(report, issue, sprintId, true, *sprint.StartDate, end); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, issue := range boardIssues {
+		if err := a.classify(report, issue, sprintId, false, *sprint.StartDate, end); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+// classify adds an issue to the right category of the report depending on its
+// history during the Sprint
+func (a *AgileReport) classify(
+	report *ReportResponse,
+	ai agileIssue,
+	sprintId string,
+	currentlyInSprint bool,
+	start, end time.Time,
+) error {
+
+	var f agileIssueFields
+	if err := decodeRawFields(ai.RawFields, &f); err != nil {
+		return errors.Wrapf(err, "error decoding fields of %s", ai.Key)
+	}
+
+	// the Sprint custom field ID differs between instances, its name doesn't
+	sprintMatcher := func(item ChangelogItem) bool {
+		return strings.EqualFold(item.Field, "Sprint")
+	}
+	current := ""
+	if currentlyInSprint {
+		current = sprintId
+	}
+
+	inSprintAt := func(t time.Time) bool {
+		if f.Created.After(t) {
+			return false
+		}
+		value := ai.Changelog.ValueAt(current, t, sprintMatcher, false)
+		return containsSprint(value, sprintId)
+	}
+
+	// entering and leaving the Sprint only happens when the issue is created
+	// or its Sprint field changes
+	var changes []time.Time
+	if f.Created.After(start) && !f.Created.After(end) {
+		changes = append(changes, f.Created.Time)
+	}
+	for _, h := range ai.Changelog.Sorted() {
+		if !h.Created.After(start) || h.Created.After(end) {
+			continue
+		}
+		for _, item := range h.Items {
+			if sprintMatcher(item) {
+				changes = append(changes, h.Created.Time)
+				break
+			}
+		}
+	}
+
+	committed := inSprintAt(start)
+	var enteredAt *time.Time
+	if committed {
+		enteredAt = &start
+	}
+
+	var leftAt *time.Time
+	for i := range changes {
+		in := inSprintAt(changes[i])
+		if in && enteredAt == nil {
+			enteredAt = &changes[i]
+		}
+		if !in && enteredAt != nil {
+			leftAt = &changes[i]
+		} else if in {
+			leftAt = nil
+		}
+	}
+
+	// never part of the Sprint
+	if enteredAt == nil {
+		return nil
+	}
+
+	estimateAt := func(t time.Time) Fieldvalue {
+		current := ""
+		if raw, ok := ai.RawFields[a.estimateField]; ok {
+			var value *float64
+			if err := json.Unmarshal(raw, &value); err == nil && value != nil {
+				current = strconv.FormatFloat(*value, 'f', -1, 64)
+			}
+		}
+		value := ai.Changelog.ValueAt(current, t, FieldMatcher(a.estimateField, "Story Points"), true)
+		estimate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		return Fieldvalue{Value: estimate, Set: err == nil}
+	}
+
+	doneAt := func(t time.Time) bool {
+		statusID := ai.Changelog.ValueAt(f.Status.ID, t, FieldMatcher("status", "status"), false)
+		return a.statusCategories[statusID] == doneStatusCategory
+	}
+
+	lastSeen := end
+	if leftAt != nil {
+		lastSeen = *leftAt
+	}
+
+	issue := Issue{
+		Key:                       ai.Key,
+		Summary:                   f.Summary,
+		TypeName:                  f.IssueType.Name,
+		TypeID:                    f.IssueType.ID,
+		StatusID:                  f.Status.ID,
+		StatusName:                f.Status.Name,
+		Status:                    f.Status,
+		Done:                      doneAt(lastSeen),
+		EstimateStatisticRequired: true,
+		EstimateStatistic: Statistic{
+			StatFieldID:    a.estimateField,
+			StatFieldValue: estimateAt(*enteredAt),
+		},
+		CurrentEstimateStatistic: Statistic{
+			StatFieldID:    a.estimateField,
+			StatFieldValue: estimateAt(lastSeen),
+		},
+	}
+	issue.ID, _ = strconv.Atoi(ai.ID)
+	issue.ProjectID, _ = strconv.Atoi(f.Project.ID)
+	if f.Priority != nil {
+		issue.PriorityName = f.Priority.Name
+	}
+	if f.Assignee != nil {
+		issue.Assignee = f.Assignee.DisplayName
+		issue.AssigneeAccountID = f.Assignee.AccountID
+		issue.AssigneeKey = f.Assignee.Key
+		issue.AssigneeName = f.Assignee.Name
+	}
+	if f.Epic != nil {
+		issue.Epic = f.Epic.Key
+	}
+
+	if !committed {
+		report.Contents.IssueKeysAddedDuringSprint[ai.Key] = true
+	}
+
+	c := &report.Contents
+
+	switch {
+	case leftAt != nil:
+		c.PuntedIssues = append(c.PuntedIssues, issue)
+		addEstimates(&c.PuntedIssuesInitialEstimateSum, &c.PuntedIssuesEstimateSum, issue)
+	case issue.Done && committed && doneAt(start):
+		c.IssuesCompletedInAnotherSprint = append(c.IssuesCompletedInAnotherSprint, issue)
+		addEstimates(&c.IssuesCompletedInAnotherSprintInitialEstimateSum, &c.IssuesCompletedInAnotherSprintEstimateSum, issue)
+	case issue.Done:
+		c.CompletedIssues = append(c.CompletedIssues, issue)
+		addEstimates(&c.CompletedIssuesInitialEstimateSum, &c.CompletedIssuesEstimateSum, issue)
+		c.AllIssuesEstimateSum.Value += issue.CurrentEstimateStatistic.StatFieldValue.Value
+	default:
+		c.IssuesNotCompletedInCurrentSprint = append(c.IssuesNotCompletedInCurrentSprint, issue)
+		addEstimates(&c.IssuesNotCompletedInitialEstimateSum, &c.IssuesNotCompletedEstimateSum, issue)
+		c.AllIssuesEstimateSum.Value += issue.CurrentEstimateStatistic.StatFieldValue.Value
+	}
+
+	return nil
+}
+
+// searchIssues fetches all pages of issues with their changelog from an Agile API issue endpoint
+func (a *AgileReport) searchIssues(ctx context.Context, endpoint string, jql string, fields []string) ([]agileIssue, error) {
+
+	var issues []agileIssue
+
+	startAt := 0
+	for {
+		u, err := url.Parse(a.EndpointPrefix)
+		if err != nil {
+			return nil, err
+		}
+		u.Path = path.Join(u.Path, endpoint)
+
+		// Adding GET parameters
+		q := u.Query()
+		q.Add("startAt", strconv.Itoa(startAt))
+		q.Add("maxResults", strconv.Itoa(agilePageSize))
+		q.Add("expand", "changelog")
+		q.Add("fields", strings.Join(fields, ","))
+		if jql != "" {
+			q.Add("jql", jql)
+		}
+		// Encode and assign back to the original query.
+		u.RawQuery = q.Encode()
+
+		req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := a.execute(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		var ar agileIssuesResponse
+		err = json.NewDecoder(resp.Body).Decode(&ar)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		issues = append(issues, ar.Issues...)
+
+		startAt += len(ar.Issues)
+		if len(ar.Issues) == 0 || startAt >= ar.Total {
+			break
+		}
+	}
+
+	// Jira Cloud only embeds the latest histories of long changelogs
+	var truncated []int
+	for i := range issues {
+		if issues[i].Changelog.Truncated() {
+			truncated = append(truncated, i)
+		}
+	}
+
+	err := pool.ForEach(len(truncated), a.concurrency, func(index int) error {
+		issue := &issues[truncated[index]]
+		if err := a.completeChangelog(ctx, issue.Key, &issue.Changelog); err != nil {
+			return errors.Wrapf(err, "error getting changelog of %s", issue.Key)
+		}
+		return nil
+	})
+
+	return issues, err
+}
+
+// loadStatusCategories fetches the status category of every status once,
+// they are needed to know whether an issue was done in the past. Failures
+// aren't kept so the next report tries again.
+func (a *AgileReport) loadStatusCategories(ctx context.Context) error {
+
+	a.statusMu.Lock()
+	defer a.statusMu.Unlock()
+
+	if a.statusCategories != nil {
+		return nil
+	}
+
+	statuses, err := a.statuses(ctx)
+	if err != nil {
+		return err
+	}
+
+	a.statusCategories = make(map[string]string, len(statuses))
+	for _, s := range statuses {
+		a.statusCategories[s.ID] = s.StatusCategory.Key
+	}
+
+	return nil
+}
+
+// decodeRawFields decodes the known fields of an issue
+func decodeRawFields(raw map[string]json.RawMessage, v interface{}) error {
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// containsSprint checks whether a Sprint field value ("12, 34") contains the Sprint ID
+func containsSprint(value, sprintId string) bool {
+	for _, id := range strings.Split(value, ",") {
+		if strings.TrimSpace(id) == sprintId {
+			return true
+		}
+	}
+	return false
+}
+
+// addEstimates adds the initial and current estimates of an issue to the report sums
+func addEstimates(initial, current *EstimateSum, issue Issue) {
+	initial.Value += issue.EstimateStatistic.StatFieldValue.Value
+	current.Value += issue.CurrentEstimateStatistic.StatFieldValue.Value
+}
+
+// formatOptionalTime formats a timestamp as RFC 3339, empty when missing
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,353 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testSprintID      = "101"
+	testEstimateField = "customfield_10005"
+
+	statusToDo       = "1"
+	statusInProgress = "3"
+	statusDone       = "5"
+)
+
+var (
+	sprintStart = at(time.October, 4, 9)
+	sprintEnd   = at(time.October, 15, 17)
+)
+
+// at returns the given UTC time of a day of 2021
+func at(month time.Month, day, hour int) time.Time {
+	return time.Date(2021, month, day, hour, 0, 0, 0, time.UTC)
+}
+
+// change is a changelog history changing a single field
+func change(t time.Time, item ChangelogItem) ChangelogHistory {
+	return ChangelogHistory{Created: Time{t}, Items: []ChangelogItem{item}}
+}
+
+func sprintChange(t time.Time, from, to string) ChangelogHistory {
+	return change(t, ChangelogItem{Field: "Sprint", FieldType: "custom", From: from, To: to})
+}
+
+func statusChange(t time.Time, from, to string) ChangelogHistory {
+	return change(t, ChangelogItem{Field: "status", FieldID: "status", From: from, To: to})
+}
+
+func estimateChange(t time.Time, from, to string) ChangelogHistory {
+	return change(t, ChangelogItem{Field: "Story Points", FieldID: testEstimateField, FromString: from, ToString: to})
+}
+
+// testAgileIssue builds an issue of the Agile API with its current status and
+// estimate, and its changelog
+func testAgileIssue(t *testing.T, key string, created time.Time, status string, estimate float64, histories ...ChangelogHistory) agileIssue {
+	t.Helper()
+
+	fields := map[string]interface{}{
+		"summary":         "Issue " + key,
+		"issuetype":       map[string]string{"id": "10001", "name": "Story"},
+		"status":          map[string]string{"id": status, "name": "Status " + status},
+		"project":         map[string]string{"id": "10000", "key": "STR"},
+		"created":         created.Format(time.RFC3339),
+		testEstimateField: estimate,
+	}
+
+	raw := make(map[string]json.RawMessage, len(fields))
+	for name, value := range fields {
+		b, err := json.Marshal(value)
+		if err != nil {
+			t.Fatalf("encoding field %s: %v", name, err)
+		}
+		raw[name] = b
+	}
+
+	return agileIssue{
+		ID:        strconv.Itoa(len(key)),
+		Key:       key,
+		RawFields: raw,
+		Changelog: Changelog{Total: len(histories), Histories: histories},
+	}
+}
+
+func newTestAgileReport() *AgileReport {
+	return &AgileReport{
+		estimateField: testEstimateField,
+		statusCategories: map[string]string{
+			statusToDo:       "new",
+			statusInProgress: "indeterminate",
+			statusDone:       doneStatusCategory,
+		},
+	}
+}
+
+// reportCategory returns the category of the report an issue ended up in
+func reportCategory(c Contents, key string) (string, *Issue) {
+	for name, issues := range map[string][]Issue{
+		"completed":      c.CompletedIssues,
+		"not completed":  c.IssuesNotCompletedInCurrentSprint,
+		"punted":         c.PuntedIssues,
+		"another Sprint": c.IssuesCompletedInAnotherSprint,
+	} {
+		for i := range issues {
+			if issues[i].Key == key {
+				return name, &issues[i]
+			}
+		}
+	}
+	return "", nil
+}
+
+func TestAgileReportClassify(t *testing.T) {
+
+	before := at(time.October, 1, 12)
+
+	tests := []struct {
+		name         string
+		issue        agileIssue
+		inSprint     bool
+		wantCategory string
+		wantAdded    bool
+		wantInitial  float64
+		wantCurrent  float64
+	}{
+		{
+			name: "committed and completed",
+			issue: testAgileIssue(t, "STR-1", at(time.September, 28, 9), statusDone, 5,
+				sprintChange(before, "", testSprintID),
+				statusChange(at(time.October, 10, 9), statusToDo, statusDone),
+			),
+			inSprint:     true,
+			wantCategory: "completed",
+			wantInitial:  5,
+			wantCurrent:  5,
+		},
+		{
+			name: "added after start",
+			issue: testAgileIssue(t, "STR-2", at(time.September, 28, 9), statusDone, 3,
+				sprintChange(at(time.October, 6, 10), "", testSprintID),
+				statusChange(at(time.October, 12, 9), statusToDo, statusDone),
+			),
+			inSprint:     true,
+			wantCategory: "completed",
+			wantAdded:    true,
+			wantInitial:  3,
+			wantCurrent:  3,
+		},
+		{
+			name:         "created in the Sprint after start",
+			issue:        testAgileIssue(t, "STR-3", at(time.October, 5, 9), statusToDo, 2),
+			inSprint:     true,
+			wantCategory: "not completed",
+			wantAdded:    true,
+			wantInitial:  2,
+			wantCurrent:  2,
+		},
+		{
+			name: "removed before end",
+			issue: testAgileIssue(t, "STR-4", at(time.September, 28, 9), statusInProgress, 8,
+				sprintChange(before, "", testSprintID),
+				sprintChange(at(time.October, 8, 9), testSprintID, "102"),
+			),
+			wantCategory: "punted",
+			wantInitial:  8,
+			wantCurrent:  8,
+		},
+		{
+			name: "removed after end",
+			issue: testAgileIssue(t, "STR-5", at(time.September, 28, 9), statusInProgress, 8,
+				sprintChange(before, "", testSprintID),
+				sprintChange(at(time.October, 18, 9), testSprintID, testSprintID+", 102"),
+			),
+			inSprint:     true,
+			wantCategory: "not completed",
+			wantInitial:  8,
+			wantCurrent:  8,
+		},
+		{
+			name: "re-estimated mid-Sprint",
+			issue: testAgileIssue(t, "STR-6", at(time.September, 28, 9), statusInProgress, 13,
+				sprintChange(before, "", testSprintID),
+				estimateChange(before, "", "3"),
+				estimateChange(at(time.October, 7, 11), "3", "8"),
+				estimateChange(at(time.October, 20, 11), "8", "13"),
+			),
+			inSprint:     true,
+			wantCategory: "not completed",
+			wantInitial:  3,
+			wantCurrent:  8,
+		},
+		{
+			name: "done before start",
+			issue: testAgileIssue(t, "STR-7", at(time.September, 20, 9), statusDone, 5,
+				sprintChange(at(time.September, 21, 9), "", testSprintID),
+				statusChange(at(time.September, 30, 9), statusInProgress, statusDone),
+			),
+			inSprint:     true,
+			wantCategory: "another Sprint",
+			wantInitial:  5,
+			wantCurrent:  5,
+		},
+		{
+			name: "reopened after done",
+			issue: testAgileIssue(t, "STR-8", at(time.September, 28, 9), statusInProgress, 5,
+				sprintChange(before, "", testSprintID),
+				statusChange(at(time.October, 9, 9), statusInProgress, statusDone),
+				statusChange(at(time.October, 11, 9), statusDone, statusInProgress),
+			),
+			inSprint:     true,
+			wantCategory: "not completed",
+			wantInitial:  5,
+			wantCurrent:  5,
+		},
+		{
+			name: "reopened after the Sprint",
+			issue: testAgileIssue(t, "STR-9", at(time.September, 28, 9), statusInProgress, 5,
+				sprintChange(before, "", testSprintID),
+				statusChange(at(time.October, 9, 9), statusInProgress, statusDone),
+				statusChange(at(time.October, 19, 9), statusDone, statusInProgress),
+			),
+			inSprint:     true,
+			wantCategory: "completed",
+			wantInitial:  5,
+			wantCurrent:  5,
+		},
+		{
+			name: "never in the Sprint",
+			issue: testAgileIssue(t, "STR-10", at(time.September, 28, 9), statusDone, 5,
+				sprintChange(before, "", "100"),
+				statusChange(at(time.October, 9, 9), statusToDo, statusDone),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			report := &ReportResponse{Contents: Contents{IssueKeysAddedDuringSprint: IssuesAdded{}}}
+
+			err := newTestAgileReport().classify(report, tt.issue, testSprintID, tt.inSprint, sprintStart, sprintEnd)
+			if err != nil {
+				t.Fatalf("classify: %v", err)
+			}
+
+			category, issue := reportCategory(report.Contents, tt.issue.Key)
+			if category != tt.wantCategory {
+				t.Fatalf("category = %q, want %q", category, tt.wantCategory)
+			}
+			if added := report.Contents.IssueKeysAddedDuringSprint[tt.issue.Key]; added != tt.wantAdded {
+				t.Errorf("added = %v, want %v", added, tt.wantAdded)
+			}
+			if issue == nil {
+				return
+			}
+			if got := issue.EstimateStatistic.StatFieldValue.Value; got != tt.wantInitial {
+				t.Errorf("initial estimate = %g, want %g", got, tt.wantInitial)
+			}
+			if got := issue.CurrentEstimateStatistic.StatFieldValue.Value; got != tt.wantCurrent {
+				t.Errorf("current estimate = %g, want %g", got, tt.wantCurrent)
+			}
+		})
+	}
+}
+
+func TestChangelogValueAt(t *testing.T) {
+
+	// histories aren't sorted by Jira
+	c := Changelog{Histories: []ChangelogHistory{
+		estimateChange(at(time.October, 7, 9), "3", "8"),
+		estimateChange(at(time.October, 5, 9), "", "3"),
+	}}
+	matcher := FieldMatcher(testEstimateField, "Story Points")
+
+	tests := []struct {
+		at   time.Time
+		want string
+	}{
+		{at(time.October, 4, 9), ""},
+		{at(time.October, 5, 9), "3"},
+		{at(time.October, 6, 9), "3"},
+		{at(time.October, 8, 9), "8"},
+	}
+
+	for _, tt := range tests {
+		if got := c.ValueAt("8", tt.at, matcher, true); got != tt.want {
+			t.Errorf("ValueAt(%s) = %q, want %q", tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestContainsSprint(t *testing.T) {
+	if !containsSprint("100, 101", testSprintID) {
+		t.Error("containsSprint() didn't find the Sprint in a list")
+	}
+	if containsSprint("1011", testSprintID) {
+		t.Error("containsSprint() matched a different Sprint ID")
+	}
+}
+
+func TestSearchIssuesCompletesTruncatedChangelogs(t *testing.T) {
+
+	var (
+		mu        sync.Mutex
+		requested []string
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rest/agile/1.0/board/1/issue", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"startAt": 0, "maxResults": 100, "total": 2, "issues": [
+			{"key": "STR-1", "changelog": {"total": 1, "histories": [{"id": "1"}]}},
+			{"key": "STR-2", "changelog": {"total": 3, "histories": [{"id": "3"}]}}
+		]}`)
+	})
+	mux.HandleFunc("/rest/api/latest/issue/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.URL.Path+"?"+r.URL.Query().Get("startAt"))
+		mu.Unlock()
+
+		if r.URL.Query().Get("startAt") == "0" {
+			fmt.Fprint(w, `{"startAt": 0, "total": 3, "isLast": false, "values": [{"id": "1"}, {"id": "2"}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"startAt": 2, "total": 3, "isLast": true, "values": [{"id": "3"}]}`)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	jc, err := New(Config{Username: "user", Password: "token", EndpointPrefix: server.URL})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	report := &AgileReport{Jira: jc, estimateField: testEstimateField}
+
+	issues, err := report.searchIssues(context.Background(), "/rest/agile/1.0/board/1/issue", "", nil)
+	if err != nil {
+		t.Fatalf("searchIssues: %v", err)
+	}
+
+	want := []string{"/rest/api/latest/issue/STR-2/changelog?0", "/rest/api/latest/issue/STR-2/changelog?2"}
+	if fmt.Sprint(requested) != fmt.Sprint(want) {
+		t.Errorf("changelog requests = %v, want %v", requested, want)
+	}
+
+	if got := len(issues[0].Changelog.Histories); got != 1 {
+		t.Errorf("STR-1 has %d histories, want 1", got)
+	}
+	var ids []string
+	for _, h := range issues[1].Changelog.Histories {
+		ids = append(ids, h.ID)
+	}
+	if fmt.Sprint(ids) != "[1 2 3]" || issues[1].Changelog.Truncated() {
+		t.Errorf("STR-2 histories = %v, want [1 2 3]", ids)
+	}
+}
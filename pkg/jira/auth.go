@@ -0,0 +1,127 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// Authenticator adds the credentials to every request sent to Jira
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// BasicAuth authenticates with a username and a password or API token (Jira Cloud)
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+// Authenticate sets the basic authorization header
+func (b BasicAuth) Authenticate(req *http.Request) error {
+	req.SetBasicAuth(b.Username, b.Password)
+	return nil
+}
+
+// BearerToken authenticates with a personal access token (Jira Data Center and Server)
+type BearerToken struct {
+	Token string
+}
+
+// Authenticate sets the bearer authorization header
+func (b BearerToken) Authenticate(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+b.Token)
+	return nil
+}
+
+// OAuth2 authenticates with OAuth 2.0 access tokens (Jira Cloud 3LO), the token
+// source is expected to refresh them when they expire
+type OAuth2 struct {
+	Source oauth2.TokenSource
+}
+
+// Authenticate sets the authorization header with a valid access token
+func (o OAuth2) Authenticate(req *http.Request) error {
+	tok, err := o.Source.Token()
+	if err != nil {
+		return err
+	}
+	tok.SetAuthHeader(req)
+	return nil
+}
+
+// WithAuthenticator replaces the basic authentication built from the username
+// and password of the configuration
+func WithAuthenticator(auth Authenticator) func(*Jira) {
+	return func(a *Jira) {
+		a.auth = auth
+	}
+}
+
+// FileTokenSource returns a token source refreshing the OAuth 2.0 token stored
+// in the given file, refreshed tokens are saved back since Atlassian rotates
+// refresh tokens
+func FileTokenSource(ctx context.Context, config *oauth2.Config, path string) (oauth2.TokenSource, error) {
+	tok, err := TokenFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &savingTokenSource{
+		source: config.TokenSource(ctx, tok),
+		path:   path,
+		last:   tok.AccessToken,
+	}, nil
+}
+
+// savingTokenSource persists the token whenever it gets refreshed
+type savingTokenSource struct {
+	mu     sync.Mutex
+	source oauth2.TokenSource
+	path   string
+	last   string
+}
+
+func (s *savingTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tok, err := s.source.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok.AccessToken != s.last {
+		if err := SaveToken(s.path, tok); err != nil {
+			return nil, err
+		}
+		s.last = tok.AccessToken
+	}
+
+	return tok, nil
+}
+
+// TokenFromFile reads an OAuth 2.0 token from a file
+func TokenFromFile(path string) (*oauth2.Token, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	tok := &oauth2.Token{}
+	err = json.NewDecoder(f).Decode(tok)
+	return tok, err
+}
+
+// SaveToken writes an OAuth 2.0 token to a file only readable by the user
+func SaveToken(path string, tok *oauth2.Token) error {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return json.NewEncoder(f).Encode(tok)
+}
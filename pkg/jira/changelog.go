@@ -0,0 +1,204 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// changelogPageSize is the number of histories requested per changelog page
+const changelogPageSize = 100
+
+// timeLayouts are the timestamp formats used by the different Jira APIs
+var timeLayouts = []string{
+	"2006-01-02T15:04:05.000-0700",
+	time.RFC3339,
+}
+
+// Time is a timestamp as returned in Jira issue fields and changelogs
+type Time struct {
+	time.Time
+}
+
+// UnmarshalJSON parses Jira timestamps, which don't always follow RFC 3339
+func (t *Time) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" || s == "null" {
+		return nil
+	}
+
+	var err error
+	for _, layout := range timeLayouts {
+		var parsed time.Time
+		if parsed, err = time.Parse(layout, s); err == nil {
+			t.Time = parsed
+			return nil
+		}
+	}
+	return err
+}
+
+// ChangelogItem is a single field change
+type ChangelogItem struct {
+	Field      string `json:"field"`
+	FieldType  string `json:"fieldtype"`
+	FieldID    string `json:"fieldId"`
+	From       string `json:"from"`
+	FromString string `json:"fromString"`
+	To         string `json:"to"`
+	ToString   string `json:"toString"`
+}
+
+// ChangelogHistory groups the field changes done at once
+type ChangelogHistory struct {
+	ID      string          `json:"id"`
+	Created Time            `json:"created"`
+	Items   []ChangelogItem `json:"items"`
+}
+
+// Changelog is the history of changes of an issue
+type Changelog struct {
+	StartAt    int                `json:"startAt"`
+	MaxResults int                `json:"maxResults"`
+	Total      int                `json:"total"`
+	Histories  []ChangelogHistory `json:"histories"`
+}
+
+// changelogPage is a page of the changelog endpoint of Jira Cloud
+type changelogPage struct {
+	StartAt    int                `json:"startAt"`
+	MaxResults int                `json:"maxResults"`
+	Total      int                `json:"total"`
+	IsLast     bool               `json:"isLast"`
+	Values     []ChangelogHistory `json:"values"`
+}
+
+// Truncated is true when Jira only embedded a page of the histories
+func (c Changelog) Truncated() bool {
+	return len(c.Histories) < c.Total
+}
+
+// Sorted returns the histories ordered from the oldest to the newest
+func (c Changelog) Sorted() []ChangelogHistory {
+	histories := append([]ChangelogHistory(nil), c.Histories...)
+	sort.SliceStable(histories, func(i, j int) bool {
+		return histories[i].Created.Before(histories[j].Created.Time)
+	})
+	return histories
+}
+
+// ValueAt returns the value of a field at the given time, undoing the changes
+// done after it starting from the current value. The value of the change is
+// taken from the "from"/"to" ids or, when fromString is set, their display strings.
+func (c Changelog) ValueAt(
+	current string,
+	t time.Time,
+	match func(ChangelogItem) bool,
+	fromString bool,
+) string {
+
+	value := current
+	histories := c.Sorted()
+
+	for i := len(histories) - 1; i >= 0; i-- {
+		if !histories[i].Created.After(t) {
+			break
+		}
+		for _, item := range histories[i].Items {
+			if !match(item) {
+				continue
+			}
+			if fromString {
+				value = item.FromString
+			} else {
+				value = item.From
+			}
+		}
+	}
+
+	return value
+}
+
+// FieldMatcher matches changelog items of the given field ID or, for older
+// Jira versions not sending field IDs, the field name
+func FieldMatcher(fieldID, fieldName string) func(ChangelogItem) bool {
+	return func(item ChangelogItem) bool {
+		if item.FieldID != "" {
+			return item.FieldID == fieldID
+		}
+		return strings.EqualFold(item.Field, fieldName)
+	}
+}
+
+// completeChangelog replaces a truncated changelog with all its histories,
+// fetched page by page from the changelog endpoint. Only Jira Cloud truncates
+// changelogs, Jira Server embeds all the histories.
+func (a *Jira) completeChangelog(ctx context.Context, issueKey string, changelog *Changelog) error {
+
+	if !changelog.Truncated() {
+		return nil
+	}
+
+	var histories []ChangelogHistory
+	for {
+		page, err := a.getChangelogPage(ctx, issueKey, len(histories))
+		if err != nil {
+			return err
+		}
+
+		histories = append(histories, page.Values...)
+
+		if len(page.Values) == 0 || page.IsLast || len(histories) >= page.Total {
+			break
+		}
+	}
+
+	changelog.StartAt = 0
+	changelog.MaxResults = len(histories)
+	changelog.Total = len(histories)
+	changelog.Histories = histories
+
+	return nil
+}
+
+// getChangelogPage fetches a page of the changelog of an issue
+func (a *Jira) getChangelogPage(ctx context.Context, issueKey string, startAt int) (*changelogPage, error) {
+
+	u, err := url.Parse(a.EndpointPrefix)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, IssueDetailsSuffix, issueKey, "changelog")
+
+	// Adding GET parameters
+	q := u.Query()
+	q.Add("startAt", strconv.Itoa(startAt))
+	q.Add("maxResults", strconv.Itoa(changelogPageSize))
+	// Encode and assign back to the original query.
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := a.execute(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var page changelogPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
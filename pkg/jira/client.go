@@ -2,10 +2,9 @@ package jira
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"fmt"
 	"net/http"
+	"sync"
 	"time"
 )
 
@@ -14,24 +13,47 @@ type Config struct {
 	Username       string
 	Password       string
 	EndpointPrefix string
+	// BrowsePrefix is the base URL of issue links, the EndpointPrefix when empty
+	// (e.g. for OAuth 2.0 apps using https://api.atlassian.com/ex/jira/{cloudid})
+	BrowsePrefix string
+}
+
+// BrowseURL returns the base URL for browsing issues in the JIRA web UI
+func (c Config) BrowseURL() string {
+	if c.BrowsePrefix != "" {
+		return c.BrowsePrefix
+	}
+	return c.EndpointPrefix
 }
 
 // Jira represents the base struct for using Jira API
 type Jira struct {
 	Config
-	client *http.Client
+	client  *http.Client
+	retry   *RetryPolicy
+	limiter *rateLimiter
+	auth    Authenticator
+	fields  FieldMapping
+
+	// concurrency is the number of requests a single call sends in parallel
+	concurrency int
+
+	issueCache    IssueCache
+	issueCacheTTL time.Duration
+	// issueUpdated keeps the latest "updated" timestamp seen of every issue,
+	// cached issues updated after it got cached are fetched again
+	issueUpdated *sync.Map
 }
 
-// New creates Jira instance
+// New creates Jira instance, using basic authentication with the configured
+// username and password unless another authenticator is given as an option
 func New(config Config, opts ...Option) (*Jira, error) {
 
-	if len(config.Username) == 0 || len(config.Password) == 0 {
-		return nil, errors.New("username and password are required")
-	}
-
 	a := Jira{
-		Config: config,
-		client: &http.Client{},
+		Config:       config,
+		client:       &http.Client{},
+		fields:       DefaultFieldMapping(),
+		issueUpdated: &sync.Map{},
 	}
 
 	for _, opt := range opts {
@@ -40,6 +62,13 @@ func New(config Config, opts ...Option) (*Jira, error) {
 		}
 	}
 
+	if a.auth == nil {
+		if len(config.Username) == 0 || len(config.Password) == 0 {
+			return nil, errors.New("username and password are required")
+		}
+		a.auth = BasicAuth{Username: config.Username, Password: config.Password}
+	}
+
 	return &a, nil
 }
 
@@ -54,6 +83,14 @@ func WithTimeout(d time.Duration) func(*Jira) {
 	}
 }
 
+// WithConcurrency sets how many requests a single call sends in parallel,
+// e.g. the changelogs fetched for an Agile API Sprint report
+func WithConcurrency(n int) func(*Jira) {
+	return func(a *Jira) {
+		a.concurrency = n
+	}
+}
+
 // WithTransport allows customer HTTP transports to be provided to the client
 func WithTransport(transport http.RoundTripper) func(*Jira) {
 	return func(a *Jira) {
@@ -71,41 +108,60 @@ func (a *Jira) execute(ctx context.Context, req *http.Request) (*http.Response,
 	req.Header.Add("Accept-Language", "en-US,en;q=0.5")
 	req.Header.Add("Content-Type", "application/json")
 
-	req.SetBasicAuth(a.Username, a.Password)
+	attempts := a.retry.attempts(req)
 
-	resp, err := a.client.Do(req)
-	if err != nil {
-		return nil, err
-	}
+	for attempt := 1; ; attempt++ {
 
-	if resp.StatusCode < 200 || resp.StatusCode > 299 {
-		return nil, handleError(resp)
-	}
+		// every attempt sends its own copy of the request, authenticated anew
+		attemptReq := req.Clone(ctx)
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq.Body = body
+		}
 
-	return resp, nil
-}
+		if err := a.auth.Authenticate(attemptReq); err != nil {
+			return nil, err
+		}
 
-// ErrorResponse represents a error/refusal response from Jira
-type ErrorResponse struct {
-	ErrorMessages []string          `json:"errorMessages"`
-	Errors        map[string]string `json:"errors"`
-}
+		if err := a.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
 
-func (e ErrorResponse) Error() string {
-	if len(e.ErrorMessages) > 0 {
-		return fmt.Sprintf("ErrorMessages: %v", e.ErrorMessages)
-	}
-	if len(e.Errors) > 0 {
-		return fmt.Sprintf("Errors: %v", e.Errors)
-	}
-	return "Unknown error"
-}
+		resp, err := a.client.Do(attemptReq)
+
+		if err == nil && resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+			return resp, nil
+		}
 
-// handleError handles 4xx and 5xx responses and transform them to ErrorResponse struct
-func handleError(r *http.Response) error {
-	var er ErrorResponse
-	if err := json.NewDecoder(r.Body).Decode(&er); err != nil {
-		return err
+		if attempt >= attempts || !retryable(ctx, resp, err) {
+			if err != nil {
+				return nil, err
+			}
+			return nil, handleError(resp)
+		}
+
+		wait := a.retry.delay(attempt, resp, time.Now())
+
+		if a.retry.Logf != nil {
+			reason := ""
+			if err != nil {
+				reason = err.Error()
+			} else {
+				reason = resp.Status
+			}
+			a.retry.Logf("%s %s failed (%s), retrying in %s (attempt %d of %d)",
+				req.Method, req.URL.Path, reason, wait.Round(time.Millisecond), attempt+1, attempts)
+		}
+
+		if resp != nil {
+			discard(resp.Body)
+		}
+
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
 	}
-	return er
 }
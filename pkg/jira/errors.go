@@ -0,0 +1,150 @@
+package jira
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBodyLength is the number of characters of the response body kept in errors
+const maxErrorBodyLength = 512
+
+// requestIDHeaders are the headers Jira and Atlassian proxies use to identify a request
+var requestIDHeaders = []string{"X-Arequestid", "X-Request-Id", "Atl-Traceid"}
+
+// ErrorResponse represents a error/refusal response from Jira
+type ErrorResponse struct {
+	ErrorMessages []string          `json:"errorMessages"`
+	Errors        map[string]string `json:"errors"`
+}
+
+func (e ErrorResponse) Error() string {
+	if len(e.ErrorMessages) > 0 {
+		return fmt.Sprintf("ErrorMessages: %v", e.ErrorMessages)
+	}
+	if len(e.Errors) > 0 {
+		return fmt.Sprintf("Errors: %v", e.Errors)
+	}
+	return "Unknown error"
+}
+
+// Error is returned for 4xx and 5xx responses from Jira, whatever the body
+// looks like (JSON error, HTML login page, proxy error or nothing at all)
+type Error struct {
+	StatusCode  int
+	Method      string
+	URL         string
+	RequestID   string
+	ContentType string
+	// Body is the beginning of the response body
+	Body string
+	// Response is the decoded Jira error, nil when the body isn't one
+	Response *ErrorResponse
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Response != nil {
+		msg += ": " + e.Response.Error()
+	} else if e.Body != "" && !e.IsHTML() {
+		msg += ": " + e.Body
+	}
+	if e.RequestID != "" {
+		msg += fmt.Sprintf(" (request ID %s)", e.RequestID)
+	}
+	return msg
+}
+
+// Unwrap exposes the decoded Jira error
+func (e *Error) Unwrap() error {
+	if e.Response == nil {
+		return nil
+	}
+	return *e.Response
+}
+
+// IsHTML checks whether Jira, or something in front of it, answered with a web page
+func (e *Error) IsHTML() bool {
+	return strings.Contains(e.ContentType, "text/html") ||
+		strings.HasPrefix(strings.TrimSpace(e.Body), "<")
+}
+
+// handleError handles 4xx and 5xx responses and transform them to Error
+func handleError(r *http.Response) error {
+	defer r.Body.Close()
+
+	e := &Error{
+		StatusCode:  r.StatusCode,
+		ContentType: r.Header.Get("Content-Type"),
+	}
+
+	if r.Request != nil {
+		e.Method = r.Request.Method
+		e.URL = r.Request.URL.Redacted()
+	}
+
+	for _, h := range requestIDHeaders {
+		if id := r.Header.Get(h); id != "" {
+			e.RequestID = id
+			break
+		}
+	}
+
+	body, _ := ioutil.ReadAll(r.Body)
+
+	var er ErrorResponse
+	if err := json.Unmarshal(body, &er); err == nil && (len(er.ErrorMessages) > 0 || len(er.Errors) > 0) {
+		e.Response = &er
+	}
+
+	e.Body = truncate(strings.Join(strings.Fields(string(body)), " "), maxErrorBodyLength)
+
+	return e
+}
+
+// truncate shortens a string to the given number of characters
+func truncate(s string, length int) string {
+	runes := []rune(s)
+	if len(runes) <= length {
+		return s
+	}
+	return string(runes[:length]) + "..."
+}
+
+// StatusCode returns the HTTP status of a Jira error, 0 for any other error
+func StatusCode(err error) int {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.StatusCode
+	}
+	return 0
+}
+
+// IsUnauthorized checks whether Jira rejected the credentials
+func IsUnauthorized(err error) bool {
+	return StatusCode(err) == http.StatusUnauthorized
+}
+
+// IsForbidden checks whether the user lacks permissions for the resource
+func IsForbidden(err error) bool {
+	return StatusCode(err) == http.StatusForbidden
+}
+
+// IsNotFound checks whether the resource doesn't exist or isn't visible to the user
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
+
+// IsRateLimited checks whether Jira is throttling the requests
+func IsRateLimited(err error) bool {
+	return StatusCode(err) == http.StatusTooManyRequests
+}
+
+// IsHTML checks whether the error response was a web page instead of an API response
+func IsHTML(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.IsHTML()
+}
@@ -0,0 +1,151 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// FieldMapping holds the IDs of the custom fields, which differ between Jira instances
+type FieldMapping struct {
+	StoryPoints string
+	EpicLink    string
+	Discipline  string
+}
+
+// DefaultFieldMapping returns the custom field IDs used when none are configured
+func DefaultFieldMapping() FieldMapping {
+	return FieldMapping{
+		StoryPoints: "customfield_10005",
+		EpicLink:    "customfield_10009",
+		Discipline:  "customfield_12142",
+	}
+}
+
+// WithFieldMapping overrides the custom field IDs, empty ones keep their default
+func WithFieldMapping(m FieldMapping) func(*Jira) {
+	return func(a *Jira) {
+		if m.StoryPoints != "" {
+			a.fields.StoryPoints = m.StoryPoints
+		}
+		if m.EpicLink != "" {
+			a.fields.EpicLink = m.EpicLink
+		}
+		if m.Discipline != "" {
+			a.fields.Discipline = m.Discipline
+		}
+	}
+}
+
+// IDs returns the configured custom field IDs
+func (f FieldMapping) IDs() []string {
+	var ids []string
+	for _, id := range []string{f.StoryPoints, f.EpicLink, f.Discipline} {
+		if id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// FieldMapping returns the custom field IDs used by the client
+func (a *Jira) FieldMapping() FieldMapping {
+	return a.fields
+}
+
+// FieldSchema describes the type of a field
+type FieldSchema struct {
+	Type     string `json:"type"`
+	Items    string `json:"items,omitempty"`
+	System   string `json:"system,omitempty"`
+	Custom   string `json:"custom,omitempty"`
+	CustomID int    `json:"customId,omitempty"`
+}
+
+// Field is the definition of a system or custom field
+type Field struct {
+	ID     string      `json:"id"`
+	Key    string      `json:"key"`
+	Name   string      `json:"name"`
+	Custom bool        `json:"custom"`
+	Schema FieldSchema `json:"schema"`
+}
+
+const (
+	// FieldListSuffix used for listing all the fields
+	FieldListSuffix = "/rest/api/2/field"
+)
+
+// FieldDefinitions contains the logic to use JIRA field API endpoints
+type FieldDefinitions struct {
+	*Jira
+}
+
+// FieldDefinitions wraps Jira field API
+func (a *Jira) FieldDefinitions() (*FieldDefinitions, error) {
+	return &FieldDefinitions{a}, nil
+}
+
+// Get fetches all the system and custom fields from Jira API
+func (a *FieldDefinitions) Get(ctx context.Context) ([]Field, error) {
+
+	u, err := url.Parse(a.EndpointPrefix)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, FieldListSuffix)
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := a.execute(ctx, req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var fields []Field
+	err = json.NewDecoder(resp.Body).Decode(&fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+// knownFieldNames are the names the mapped custom fields usually have
+var knownFieldNames = map[string][]string{
+	"StoryPoints": {"Story Points", "Story point estimate"},
+	"EpicLink":    {"Epic Link"},
+	"Discipline":  {"Discipline"},
+}
+
+// SuggestFieldMapping looks up the custom fields by their usual names, the
+// returned mapping leaves empty the fields which couldn't be found
+func SuggestFieldMapping(fields []Field) FieldMapping {
+
+	find := func(key string) string {
+		for _, name := range knownFieldNames[key] {
+			for _, f := range fields {
+				if f.Custom && strings.EqualFold(f.Name, name) {
+					return f.ID
+				}
+			}
+		}
+		return ""
+	}
+
+	return FieldMapping{
+		StoryPoints: find("StoryPoints"),
+		EpicLink:    find("EpicLink"),
+		Discipline:  find("Discipline"),
+	}
+}
@@ -0,0 +1,79 @@
+package jira
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// CachedIssue is an issue payload as returned by the API, kept between runs.
+// The payload is decoded again on every read so changes to the field mapping
+// don't require clearing the cache.
+type CachedIssue struct {
+	Payload json.RawMessage `json:"payload"`
+	ETag    string          `json:"etag,omitempty"`
+	Updated string          `json:"updated,omitempty"`
+	// Fields are the fields requested for the payload, all of them when empty
+	Fields    []string  `json:"fields,omitempty"`
+	FetchedAt time.Time `json:"fetchedAt"`
+}
+
+// Covers checks whether the payload includes the given fields
+func (c CachedIssue) Covers(fields []string) bool {
+	if len(c.Fields) == 0 {
+		return true
+	}
+	cached := make(map[string]bool, len(c.Fields))
+	for _, f := range c.Fields {
+		cached[f] = true
+	}
+	for _, f := range fields {
+		if f == AllFields || !cached[f] {
+			return false
+		}
+	}
+	return true
+}
+
+// Expired checks whether the entry is older than the TTL, entries never expire with a zero TTL
+func (c CachedIssue) Expired(ttl time.Duration, now time.Time) bool {
+	return ttl > 0 && now.Sub(c.FetchedAt) > ttl
+}
+
+// OlderThan checks whether the issue was updated in Jira after it got cached,
+// given the "updated" timestamp of the issue
+func (c CachedIssue) OlderThan(updated string) bool {
+	if updated == "" || c.Updated == "" {
+		return false
+	}
+	var cached, current Time
+	if cached.UnmarshalJSON([]byte(c.Updated)) != nil || current.UnmarshalJSON([]byte(updated)) != nil {
+		return c.Updated != updated
+	}
+	return cached.Before(current.Time)
+}
+
+// IssueCache stores issue payloads, implementations must be safe for concurrent use
+type IssueCache interface {
+	Get(key string) (CachedIssue, bool)
+	Put(key string, issue CachedIssue)
+}
+
+// WithIssueCache caches issue details, entries older than the TTL are
+// revalidated with Jira using their ETag
+func WithIssueCache(cache IssueCache, ttl time.Duration) func(*Jira) {
+	return func(a *Jira) {
+		a.issueCache = cache
+		a.issueCacheTTL = ttl
+	}
+}
+
+// updatedField extracts the "updated" timestamp from an issue payload
+func updatedField(payload []byte) string {
+	var issue struct {
+		Fields struct {
+			Updated string `json:"updated"`
+		} `json:"fields"`
+	}
+	json.Unmarshal(payload, &issue)
+	return issue.Fields.Updated
+}
@@ -0,0 +1,122 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// IssueChangelogSuffix used for getting issues with their changelog
+const IssueChangelogSuffix = "/rest/api/2/issue/"
+
+// changelogFields are the issue fields needed along with the changelog
+var changelogFields = []string{"summary", "issuetype", "status", "created", "resolutiondate"}
+
+// IssueHistoryFields are the issue fields returned along with the changelog
+type IssueHistoryFields struct {
+	Summary        string    `json:"summary"`
+	Issuetype      IssueType `json:"issuetype"`
+	Status         Status    `json:"status"`
+	Created        Time      `json:"created"`
+	ResolutionDate *Time     `json:"resolutiondate"`
+}
+
+// IssueHistory is an issue with its complete changelog
+type IssueHistory struct {
+	ID        string             `json:"id"`
+	Key       string             `json:"key"`
+	Fields    IssueHistoryFields `json:"fields"`
+	Changelog Changelog          `json:"changelog"`
+}
+
+// IssueChangelogs contains the logic to fetch the changelog of JIRA issues
+type IssueChangelogs struct {
+	*Jira
+}
+
+// IssueChangelogs wraps Jira issue changelog API
+func (a *Jira) IssueChangelogs() (*IssueChangelogs, error) {
+	return &IssueChangelogs{a}, nil
+}
+
+// issueURL returns the URL of an issue or one of its sub-resources
+func (a *IssueChangelogs) issueURL(elem ...string) (*url.URL, error) {
+	u, err := url.Parse(a.EndpointPrefix)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(append([]string{u.Path, IssueChangelogSuffix}, elem...)...)
+	return u, nil
+}
+
+// Get fetches an issue with its changelog. Jira only embeds a page of
+// histories in the issue, the complete changelog is fetched from the changelog
+// endpoint when it's truncated.
+func (a *IssueChangelogs) Get(ctx context.Context, issueKey string) (*IssueHistory, error) {
+
+	u, err := a.issueURL(issueKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Adding GET parameters
+	q := u.Query()
+	q.Add("expand", "changelog")
+	q.Add("fields", strings.Join(changelogFields, ","))
+	// Encode and assign back to the original query.
+	u.RawQuery = q.Encode()
+
+	var history IssueHistory
+	if err := a.getJSON(ctx, u, &history); err != nil {
+		return nil, err
+	}
+
+	if err := a.completeChangelog(ctx, issueKey, &history.Changelog); err != nil {
+		return nil, err
+	}
+
+	return &history, nil
+}
+
+// Statuses fetches every issue status with its status category
+func (a *IssueChangelogs) Statuses(ctx context.Context) ([]Status, error) {
+	return a.Jira.statuses(ctx)
+}
+
+// statuses fetches every issue status with its status category
+func (a *Jira) statuses(ctx context.Context) ([]Status, error) {
+
+	u, err := url.Parse(a.EndpointPrefix)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, StatusSuffix)
+
+	var statuses []Status
+	if err := a.getJSON(ctx, u, &statuses); err != nil {
+		return nil, err
+	}
+
+	return statuses, nil
+}
+
+// getJSON requests the URL and decodes the JSON response
+func (a *Jira) getJSON(ctx context.Context, u *url.URL, v interface{}) error {
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := a.execute(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -3,9 +3,11 @@ package jira
 import (
 	"context"
 	"encoding/json"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"path"
+	"time"
 )
 
 // Component associated with the task
@@ -28,14 +30,27 @@ type IssueType struct {
 	Subtask     bool   `json:"subtask"`
 }
 
-// Fields inside a JIRA issue
+// User assigned to or reporting an issue
+type User struct {
+	DisplayName  string `json:"displayName"`
+	Name         string `json:"name,omitempty"`
+	Key          string `json:"key,omitempty"`
+	AccountID    string `json:"accountId,omitempty"`
+	EmailAddress string `json:"emailAddress,omitempty"`
+}
+
+// Fields inside a JIRA issue, the custom ones are decoded following the FieldMapping
 type Fields struct {
 	Components  []Component `json:"components"`
-	StoryPoints float64     `json:"customfield_10005"`
-	ParentKey   string      `json:"customfield_10009"`
-	Discipline  Discipline  `json:"customfield_12142"`
+	StoryPoints float64     `json:"-"`
+	ParentKey   string      `json:"-"`
+	Discipline  Discipline  `json:"-"`
 	Issuetype   IssueType   `json:"issuetype"`
 	Summary     string      `json:"summary"`
+	Labels      []string    `json:"labels"`
+	Assignee    *User       `json:"assignee"`
+	// Raw contains the undecoded value of every field by ID
+	Raw map[string]json.RawMessage `json:"-"`
 }
 
 // SimpleIssue simplified version of API response for issues
@@ -45,6 +60,41 @@ type SimpleIssue struct {
 	Fields Fields `json:"fields"`
 }
 
+// rawIssue is an issue as returned by the API before decoding its fields
+type rawIssue struct {
+	ID     string                     `json:"id"`
+	Key    string                     `json:"key"`
+	Fields map[string]json.RawMessage `json:"fields"`
+}
+
+// decode decodes the issue fields, the custom ones following the mapping.
+// Custom fields with unexpected values (e.g. removed from the screen) are left empty.
+func (r rawIssue) decode(mapping FieldMapping) (*SimpleIssue, error) {
+
+	issue := SimpleIssue{ID: r.ID, Key: r.Key}
+
+	if err := decodeRawFields(r.Fields, &issue.Fields); err != nil {
+		return nil, err
+	}
+
+	issue.Fields.Raw = r.Fields
+
+	decodeCustomField(r.Fields, mapping.StoryPoints, &issue.Fields.StoryPoints)
+	decodeCustomField(r.Fields, mapping.EpicLink, &issue.Fields.ParentKey)
+	decodeCustomField(r.Fields, mapping.Discipline, &issue.Fields.Discipline)
+
+	return &issue, nil
+}
+
+// decodeCustomField decodes a single field if present
+func decodeCustomField(raw map[string]json.RawMessage, id string, v interface{}) {
+	value, ok := raw[id]
+	if !ok || id == "" {
+		return
+	}
+	json.Unmarshal(value, v)
+}
+
 const (
 	// IssueDetailsSuffix used for getting issue details
 	IssueDetailsSuffix = "/rest/api/latest/issue/"
@@ -71,33 +121,155 @@ func (a *IssueDetails) issueDetailsURL(issueId string) (*url.URL, error) {
 
 }
 
-// Get fetches issue details from Jira API
+// Get fetches issue details from Jira API, going through the issue cache when configured
 func (a *IssueDetails) Get(ctx context.Context, issueId string) (*SimpleIssue, error) {
 
-	url, err := a.issueDetailsURL(issueId)
+	if a.issueCache == nil {
+		payload, _, err := a.fetch(ctx, issueId, "")
+		if err != nil {
+			return nil, err
+		}
+		return a.decode(payload)
+	}
+
+	cached, ok := a.issueCache.Get(issueId)
+	if ok && a.fresh(issueId, cached) {
+		return a.decode(cached.Payload)
+	}
+
+	etag := ""
+	if ok {
+		etag = cached.ETag
+	}
+
+	payload, newETag, err := a.fetch(ctx, issueId, etag)
+
+	if ok && StatusCode(err) == http.StatusNotModified {
+		cached.FetchedAt = time.Now()
+		a.issueCache.Put(issueId, cached)
+		return a.decode(cached.Payload)
+	}
+
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest(http.MethodGet, url.String(), nil)
+	updated := updatedField(payload)
+	a.SetUpdated(issueId, updated)
+
+	a.issueCache.Put(issueId, CachedIssue{
+		Payload:   payload,
+		ETag:      newETag,
+		Updated:   updated,
+		FetchedAt: time.Now(),
+	})
+
+	return a.decode(payload)
+}
 
+// Store caches an issue payload obtained by other means (e.g. a search) with
+// the given fields, all of them when none are given, and returns the decoded
+// issue. A cached issue which wasn't updated since and has the fields is kept,
+// since it may have more fields than the given payload.
+func (a *IssueDetails) Store(payload json.RawMessage, fields ...string) (*SimpleIssue, error) {
+	issue, err := a.decode(payload)
 	if err != nil {
 		return nil, err
 	}
+	if a.issueCache == nil {
+		return issue, nil
+	}
+
+	updated := updatedField(payload)
+	a.SetUpdated(issue.Key, updated)
+
+	cached, ok := a.issueCache.Get(issue.Key)
+	if ok && updated != "" && cached.Updated != "" && !cached.OlderThan(updated) && cached.Covers(fields) {
+		cached.FetchedAt = time.Now()
+		a.issueCache.Put(issue.Key, cached)
+		return a.decode(cached.Payload)
+	}
+
+	if len(fields) == 1 && fields[0] == AllFields {
+		fields = nil
+	}
+
+	a.issueCache.Put(issue.Key, CachedIssue{
+		Payload:   payload,
+		Updated:   updated,
+		Fields:    fields,
+		FetchedAt: time.Now(),
+	})
+	return issue, nil
+}
+
+// Cached checks whether the issue is in the cache with the given fields, any
+// when none are given, and doesn't need to be fetched again
+func (a *IssueDetails) Cached(issueId string, fields ...string) bool {
+	if a.issueCache == nil {
+		return false
+	}
+	cached, ok := a.issueCache.Get(issueId)
+	return ok && a.fresh(issueId, cached) && cached.Covers(fields)
+}
+
+// SetUpdated records the "updated" timestamp of an issue as seen in Jira
+// (e.g. in a search), invalidating the cached issue when it's older
+func (a *IssueDetails) SetUpdated(issueId string, updated string) {
+	if updated != "" {
+		a.issueUpdated.Store(issueId, updated)
+	}
+}
+
+// fresh checks whether a cached issue can be used without asking Jira: it's
+// within the TTL and wasn't updated after it got cached
+func (a *IssueDetails) fresh(issueId string, cached CachedIssue) bool {
+	if cached.Expired(a.issueCacheTTL, time.Now()) {
+		return false
+	}
+	updated, ok := a.issueUpdated.Load(issueId)
+	return !ok || !cached.OlderThan(updated.(string))
+}
+
+// fetch requests the issue payload, conditionally when an ETag is given
+func (a *IssueDetails) fetch(ctx context.Context, issueId string, etag string) (json.RawMessage, string, error) {
+
+	url, err := a.issueDetailsURL(issueId)
+	if err != nil {
+		return nil, "", err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url.String(), nil)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
 
 	resp, err := a.execute(ctx, req)
 
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	defer resp.Body.Close()
 
-	var ar SimpleIssue
-	err = json.NewDecoder(resp.Body).Decode(&ar)
+	payload, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	return &ar, nil
+	return payload, resp.Header.Get("ETag"), nil
+}
+
+// decode decodes an issue payload following the field mapping
+func (a *IssueDetails) decode(payload []byte) (*SimpleIssue, error) {
+	var ar rawIssue
+	if err := json.Unmarshal(payload, &ar); err != nil {
+		return nil, err
+	}
+	return ar.decode(a.fields)
 }
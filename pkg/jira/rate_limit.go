@@ -0,0 +1,50 @@
+package jira
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rateLimiter spaces out requests evenly, it's shared by all the services
+// created from the same client
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// WithRateLimit limits the requests sent to Jira, across all goroutines, to
+// the given number per second
+func WithRateLimit(requestsPerSecond float64) func(*Jira) {
+	return func(a *Jira) {
+		if requestsPerSecond <= 0 {
+			a.limiter = nil
+			return
+		}
+		a.limiter = &rateLimiter{
+			interval: time.Duration(float64(time.Second) / requestsPerSecond),
+		}
+	}
+}
+
+// wait blocks until the next request is allowed or the context is cancelled
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	return sleep(ctx, delay)
+}
@@ -0,0 +1,165 @@
+package jira
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures how failed idempotent requests are retried
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry, doubled on every attempt
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between attempts, including those requested by Jira
+	MaxDelay time.Duration
+	// Logf, when set, is called before every retry
+	Logf func(format string, args ...interface{})
+}
+
+// DefaultRetryPolicy returns a policy suitable for syncing many Sprints in a row
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 5,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    30 * time.Second,
+	}
+}
+
+// WithRetry enables retrying idempotent requests failing with network errors,
+// rate limiting (429) or temporary server errors (502, 503, 504)
+func WithRetry(policy RetryPolicy) func(*Jira) {
+	return func(a *Jira) {
+		a.retry = &policy
+	}
+}
+
+// attempts returns the number of attempts allowed for the request
+func (p *RetryPolicy) attempts(req *http.Request) int {
+	if p == nil || p.MaxAttempts < 1 {
+		return 1
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return p.MaxAttempts
+	default:
+		return 1
+	}
+}
+
+// retryable checks whether the outcome of an attempt is worth retrying
+func retryable(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		// cancellation by the caller is final
+		return ctx.Err() == nil
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// delay returns how long to wait before the next attempt. Delays requested
+// by Jira through Retry-After or an exhausted X-RateLimit-* quota take
+// precedence over the exponential backoff with jitter, both are capped at
+// MaxDelay.
+func (p *RetryPolicy) delay(attempt int, resp *http.Response, now time.Time) time.Duration {
+
+	if resp != nil {
+		if d, ok := retryAfter(resp.Header, now); ok {
+			return p.capped(d)
+		}
+		if d, ok := rateLimitReset(resp.Header, now); ok {
+			return p.capped(d)
+		}
+	}
+
+	backoff := p.BaseDelay << uint(attempt-1)
+	if backoff <= 0 || (p.MaxDelay > 0 && backoff > p.MaxDelay) {
+		backoff = p.MaxDelay
+	}
+	if backoff <= 0 {
+		return 0
+	}
+
+	// jitter between half and the whole backoff so concurrent clients spread out
+	half := int64(backoff) / 2
+	return time.Duration(half + rand.Int63n(half+1))
+}
+
+// capped limits a delay requested by Jira to MaxDelay
+func (p *RetryPolicy) capped(d time.Duration) time.Duration {
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return d
+}
+
+// retryAfter parses the Retry-After header, either in seconds or as an HTTP date
+func retryAfter(h http.Header, now time.Time) (time.Duration, bool) {
+	value := h.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return nonNegative(t.Sub(now)), true
+	}
+	return 0, false
+}
+
+// rateLimitReset waits for the reset of an exhausted Jira rate limit quota
+func rateLimitReset(h http.Header, now time.Time) (time.Duration, bool) {
+	if h.Get("X-RateLimit-Remaining") != "0" {
+		return 0, false
+	}
+	value := h.Get("X-RateLimit-Reset")
+	if value == "" {
+		return 0, false
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04Z07:00"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return nonNegative(t.Sub(now)), true
+		}
+	}
+	if epoch, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return nonNegative(time.Unix(epoch, 0).Sub(now)), true
+	}
+	return 0, false
+}
+
+func nonNegative(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// sleep waits for the given duration unless the context is cancelled first
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// discard drains and closes a response body so the connection can be reused
+func discard(body io.ReadCloser) {
+	io.Copy(ioutil.Discard, body)
+	body.Close()
+}
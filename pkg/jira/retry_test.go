@@ -0,0 +1,94 @@
+package jira
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+
+	now := time.Date(2021, time.October, 4, 9, 0, 0, 0, time.UTC)
+	policy := RetryPolicy{BaseDelay: time.Second, MaxDelay: 30 * time.Second}
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+	}{
+		{"Retry-After in seconds", http.Header{"Retry-After": {"5"}}, 5 * time.Second},
+		{"Retry-After capped", http.Header{"Retry-After": {"3600"}}, 30 * time.Second},
+		{
+			"Retry-After as a date",
+			http.Header{"Retry-After": {now.Add(10 * time.Second).Format(http.TimeFormat)}},
+			10 * time.Second,
+		},
+		{
+			"exhausted quota capped",
+			http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {now.Add(time.Hour).Format(time.RFC3339)}},
+			30 * time.Second,
+		},
+		{
+			"reset in the past",
+			http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {strconv.FormatInt(now.Add(-time.Minute).Unix(), 10)}},
+			0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: tt.header}
+			if got := policy.delay(1, resp, now); got != tt.want {
+				t.Errorf("delay() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	// exponential backoff with jitter between half and the whole backoff
+	for attempt, max := range map[int]time.Duration{1: time.Second, 3: 4 * time.Second, 10: 30 * time.Second} {
+		if got := policy.delay(attempt, nil, now); got < max/2 || got > max {
+			t.Errorf("delay(%d) = %s, want between %s and %s", attempt, got, max/2, max)
+		}
+	}
+}
+
+func TestExecuteAuthenticatesEveryAttempt(t *testing.T) {
+
+	var attempts int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if user, password, ok := r.BasicAuth(); !ok || user != "user" || password != "token" {
+			t.Errorf("attempt %d isn't authenticated", attempts)
+		}
+		if attempts < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	jc, err := New(
+		Config{Username: "user", Password: "token", EndpointPrefix: server.URL},
+		WithRetry(RetryPolicy{MaxAttempts: 3}),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := jc.execute(context.Background(), req)
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	resp.Body.Close()
+
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+}
@@ -0,0 +1,169 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	// SearchSuffix used for searching issues with JQL
+	SearchSuffix = "/rest/api/2/search"
+
+	// searchPageSize is the number of issues requested per page
+	searchPageSize = 100
+
+	// AllFields selects every field of the issues, as returned by the issue endpoint
+	AllFields = "*all"
+)
+
+// SearchResponse is a page of issues matching a JQL query
+type SearchResponse struct {
+	StartAt    int               `json:"startAt"`
+	MaxResults int               `json:"maxResults"`
+	Total      int               `json:"total"`
+	Issues     []json.RawMessage `json:"issues"`
+}
+
+// Search contains the logic to use JIRA search API endpoints
+type Search struct {
+	*Jira
+}
+
+// Search wraps Jira search API
+func (a *Jira) Search() (*Search, error) {
+	return &Search{a}, nil
+}
+
+// searchURL returns the URL for the JIRA search API
+func (a *Search) searchURL() (*url.URL, error) {
+	u, err := url.Parse(a.EndpointPrefix)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, SearchSuffix)
+	return u, nil
+}
+
+// GetPage fetches a single page of issues matching the JQL query with the
+// given fields (the navigable ones when none are given). Unknown issue keys
+// in the query are reported as warnings by Jira instead of failing it.
+func (a *Search) GetPage(ctx context.Context, jql string, fields []string, startAt int) (*SearchResponse, error) {
+
+	url, err := a.searchURL()
+	if err != nil {
+		return nil, err
+	}
+
+	// Adding GET parameters
+	q := url.Query()
+	q.Add("jql", jql)
+	q.Add("startAt", strconv.Itoa(startAt))
+	q.Add("maxResults", strconv.Itoa(searchPageSize))
+	q.Add("validateQuery", "warn")
+	if len(fields) > 0 {
+		q.Add("fields", strings.Join(fields, ","))
+	}
+	// Encode and assign back to the original query.
+	url.RawQuery = q.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, url.String(), nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := a.execute(ctx, req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var ar SearchResponse
+	err = json.NewDecoder(resp.Body).Decode(&ar)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ar, nil
+}
+
+// GetRaw fetches all the pages of issues matching the JQL query, undecoded
+func (a *Search) GetRaw(ctx context.Context, jql string, fields []string) ([]json.RawMessage, error) {
+
+	var issues []json.RawMessage
+
+	startAt := 0
+	for {
+		page, err := a.GetPage(ctx, jql, fields, startAt)
+		if err != nil {
+			return nil, err
+		}
+
+		issues = append(issues, page.Issues...)
+
+		startAt += len(page.Issues)
+		if len(page.Issues) == 0 || startAt >= page.Total {
+			break
+		}
+	}
+
+	return issues, nil
+}
+
+// Get fetches all the issues matching the JQL query, decoding their custom
+// fields following the field mapping
+func (a *Search) Get(ctx context.Context, jql string, fields []string) ([]SimpleIssue, error) {
+
+	raw, err := a.GetRaw(ctx, jql, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	issues := make([]SimpleIssue, len(raw))
+	for i, payload := range raw {
+		var ri rawIssue
+		if err := json.Unmarshal(payload, &ri); err != nil {
+			return nil, err
+		}
+		issue, err := ri.decode(a.fields)
+		if err != nil {
+			return nil, err
+		}
+		issues[i] = *issue
+	}
+
+	return issues, nil
+}
+
+// GetUpdated fetches the "updated" timestamp of the issues matching the JQL
+// query by issue key, a cheap way of finding out which issues changed
+func (a *Search) GetUpdated(ctx context.Context, jql string) (map[string]string, error) {
+
+	raw, err := a.GetRaw(ctx, jql, []string{"updated"})
+	if err != nil {
+		return nil, err
+	}
+
+	updated := make(map[string]string, len(raw))
+	for _, payload := range raw {
+		var ri rawIssue
+		if err := json.Unmarshal(payload, &ri); err != nil {
+			return nil, err
+		}
+		updated[ri.Key] = updatedField(payload)
+	}
+
+	return updated, nil
+}
+
+// KeysJQL returns a JQL query matching the given issue keys
+func KeysJQL(keys []string) string {
+	return "key in (" + strings.Join(keys, ",") + ")"
+}
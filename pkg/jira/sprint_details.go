@@ -0,0 +1,87 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+)
+
+// AgileSprint is the Sprint representation of the Jira Agile REST API
+type AgileSprint struct {
+	ID            int        `json:"id"`
+	Self          string     `json:"self"`
+	State         string     `json:"state"`
+	Name          string     `json:"name"`
+	StartDate     *time.Time `json:"startDate,omitempty"`
+	EndDate       *time.Time `json:"endDate,omitempty"`
+	CompleteDate  *time.Time `json:"completeDate,omitempty"`
+	OriginBoardID int        `json:"originBoardId"`
+	Goal          string     `json:"goal"`
+}
+
+// ClosingDate returns the date the Sprint was completed, falling back to its planned end date
+func (s AgileSprint) ClosingDate() *time.Time {
+	if s.CompleteDate != nil {
+		return s.CompleteDate
+	}
+	return s.EndDate
+}
+
+const (
+	// SprintDetailsSuffix used for getting Sprint details from the Agile API
+	SprintDetailsSuffix = "/rest/agile/1.0/sprint/"
+)
+
+// SprintDetails contains the logic to use JIRA Agile Sprint API endpoints
+type SprintDetails struct {
+	*Jira
+}
+
+// SprintDetails wraps Jira Agile Sprint API
+func (a *Jira) SprintDetails() (*SprintDetails, error) {
+	return &SprintDetails{a}, nil
+}
+
+// sprintDetailsURL returns the URL for a Sprint in the JIRA Agile API
+func (a *SprintDetails) sprintDetailsURL(sprintId string) (*url.URL, error) {
+	u, err := url.Parse(a.EndpointPrefix)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, SprintDetailsSuffix, sprintId)
+	return u, nil
+}
+
+// Get fetches Sprint details including its dates from Jira Agile API
+func (a *SprintDetails) Get(ctx context.Context, sprintId string) (*AgileSprint, error) {
+
+	url, err := a.sprintDetailsURL(sprintId)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url.String(), nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := a.execute(ctx, req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var ar AgileSprint
+	err = json.NewDecoder(resp.Body).Decode(&ar)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ar, nil
+}
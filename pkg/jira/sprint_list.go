@@ -7,6 +7,22 @@ import (
 	"net/url"
 	"path"
 	"strconv"
+	"strings"
+)
+
+// SprintLister lists the Sprints of a board
+type SprintLister interface {
+	Get(ctx context.Context, rapidViewId string, includeFutureSprints bool) (*SprintsResponse, error)
+	List(ctx context.Context, rapidViewId string, states ...string) ([]AgileSprint, error)
+}
+
+const (
+	// SprintStateActive is the Agile API state of a started Sprint
+	SprintStateActive = "active"
+	// SprintStateClosed is the Agile API state of a completed Sprint
+	SprintStateClosed = "closed"
+	// SprintStateFuture is the Agile API state of a planned Sprint
+	SprintStateFuture = "future"
 )
 
 type BasicSprint struct {
@@ -85,3 +101,144 @@ func (a *SprintList) Get(ctx context.Context, rapidViewId string, includeFutureS
 
 	return &ar, nil
 }
+
+// List fetches the board Sprints in the given states ("active", "closed",
+// "future"), all states when none is given. The Greenhopper Sprint list is
+// neither paginated nor includes dates, so the Agile API is used instead.
+func (a *SprintList) List(ctx context.Context, rapidViewId string, states ...string) ([]AgileSprint, error) {
+	agileSprints, err := a.AgileSprints()
+	if err != nil {
+		return nil, err
+	}
+	return agileSprints.List(ctx, rapidViewId, states...)
+}
+
+// BoardSprintsResponse is a page of Sprints from the Jira Agile API
+type BoardSprintsResponse struct {
+	MaxResults int           `json:"maxResults"`
+	StartAt    int           `json:"startAt"`
+	IsLast     bool          `json:"isLast"`
+	Values     []AgileSprint `json:"values"`
+}
+
+const (
+	// BoardSuffix used for the board endpoints of the Agile API
+	BoardSuffix = "/rest/agile/1.0/board/"
+)
+
+// AgileSprintList lists board Sprints using the official Jira Agile API
+type AgileSprintList struct {
+	*Jira
+}
+
+// AgileSprints wraps Jira Agile board Sprint API
+func (a *Jira) AgileSprints() (*AgileSprintList, error) {
+	return &AgileSprintList{a}, nil
+}
+
+// boardSprintsURL returns the URL for the Sprints of a board in the Agile API
+func (a *AgileSprintList) boardSprintsURL(boardId string) (*url.URL, error) {
+	u, err := url.Parse(a.EndpointPrefix)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, BoardSuffix, boardId, "sprint")
+	return u, nil
+}
+
+// GetPage fetches a single page of board Sprints in the given states
+// ("active", "closed", "future"), all states when none is given
+func (a *AgileSprintList) GetPage(ctx context.Context, boardId string, startAt int, states ...string) (*BoardSprintsResponse, error) {
+
+	url, err := a.boardSprintsURL(boardId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Adding GET parameters
+	q := url.Query()
+	q.Add("startAt", strconv.Itoa(startAt))
+	if len(states) > 0 {
+		q.Add("state", strings.Join(states, ","))
+	}
+	// Encode and assign back to the original query.
+	url.RawQuery = q.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, url.String(), nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := a.execute(ctx, req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var ar BoardSprintsResponse
+	err = json.NewDecoder(resp.Body).Decode(&ar)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ar, nil
+}
+
+// List fetches all the board Sprints in the given states ("active", "closed",
+// "future") following the pages of the Agile API, all states when none is given
+func (a *AgileSprintList) List(ctx context.Context, boardId string, states ...string) ([]AgileSprint, error) {
+
+	var result []AgileSprint
+
+	startAt := 0
+	for {
+		page, err := a.GetPage(ctx, boardId, startAt, states...)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, page.Values...)
+
+		if page.IsLast || len(page.Values) == 0 {
+			break
+		}
+		startAt += len(page.Values)
+	}
+
+	return result, nil
+}
+
+// Get fetches the active and closed board Sprints, and the future ones when
+// requested, in the same shape as the Greenhopper Sprint list
+func (a *AgileSprintList) Get(ctx context.Context, boardId string, includeFutureSprints bool) (*SprintsResponse, error) {
+
+	states := []string{SprintStateActive, SprintStateClosed}
+	if includeFutureSprints {
+		states = append(states, SprintStateFuture)
+	}
+
+	sprints, err := a.List(ctx, boardId, states...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := SprintsResponse{}
+	if id, err := strconv.Atoi(boardId); err == nil {
+		result.RapidViewID = id
+	}
+
+	for i, s := range sprints {
+		result.Sprints = append(result.Sprints, BasicSprint{
+			ID:       s.ID,
+			Sequence: i + 1,
+			Name:     s.Name,
+			State:    strings.ToUpper(s.State),
+			Goal:     s.Goal,
+		})
+	}
+
+	return &result, nil
+}
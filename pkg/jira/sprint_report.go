@@ -11,6 +11,42 @@ import (
 type Fieldvalue struct {
 	Value float64 `json:"value,omitempty"`
 	Text  string  `json:"text,omitempty"`
+	// Set is true when the value is present, telling apart an estimate of 0
+	// from a missing one
+	Set bool `json:"-"`
+}
+
+// fieldvalueJSON is the JSON shape of a Fieldvalue, with an optional value
+type fieldvalueJSON struct {
+	Value *float64 `json:"value,omitempty"`
+	Text  string   `json:"text,omitempty"`
+}
+
+// UnmarshalJSON keeps whether the value is present
+func (f *Fieldvalue) UnmarshalJSON(data []byte) error {
+	var v fieldvalueJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*f = Fieldvalue{Text: v.Text}
+	if v.Value != nil {
+		f.Value, f.Set = *v.Value, true
+	}
+	return nil
+}
+
+// MarshalJSON writes the value when present, even if it's 0
+func (f Fieldvalue) MarshalJSON() ([]byte, error) {
+	v := fieldvalueJSON{Text: f.Text}
+	if f.Estimated() {
+		v.Value = &f.Value
+	}
+	return json.Marshal(v)
+}
+
+// Estimated is true when the value is present
+func (f Fieldvalue) Estimated() bool {
+	return f.Set || f.Value != 0
 }
 
 type Statistic struct {
@@ -0,0 +1,56 @@
+// Package pool runs work over a bounded number of goroutines
+package pool
+
+import "sync"
+
+// ForEach calls fn for every index in [0, n) using at most the given number
+// of goroutines, returning the first error found. Once an error happens the
+// remaining indexes are skipped.
+func ForEach(n int, concurrency int, fn func(i int) error) error {
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if concurrency > n {
+		concurrency = n
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+
+	indexes := make(chan int)
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := fn(i); err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+		if failed {
+			break
+		}
+		indexes <- i
+	}
+	close(indexes)
+
+	wg.Wait()
+
+	return firstErr
+}
@@ -0,0 +1,62 @@
+package pool
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestForEach(t *testing.T) {
+
+	var running, maxRunning int32
+	done := make([]bool, 20)
+
+	err := ForEach(len(done), 3, func(i int) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+				break
+			}
+		}
+		done[i] = true
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEach: %v", err)
+	}
+
+	for i, ok := range done {
+		if !ok {
+			t.Errorf("index %d skipped", i)
+		}
+	}
+	if maxRunning > 3 {
+		t.Errorf("%d calls ran in parallel, want at most 3", maxRunning)
+	}
+}
+
+func TestForEachStopsOnError(t *testing.T) {
+
+	failure := errors.New("failure")
+	var calls int32
+
+	err := ForEach(100, 1, func(i int) error {
+		atomic.AddInt32(&calls, 1)
+		if i == 2 {
+			return failure
+		}
+		return nil
+	})
+	if err != failure {
+		t.Errorf("ForEach() error = %v, want %v", err, failure)
+	}
+	if calls > 4 {
+		t.Errorf("%d calls after the error, want the remaining indexes skipped", calls)
+	}
+
+	if err := ForEach(0, 4, func(int) error { return failure }); err != nil {
+		t.Errorf("ForEach() without indexes = %v, want nil", err)
+	}
+}
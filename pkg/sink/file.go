@@ -0,0 +1,94 @@
+package sink
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// CSV writes every table to <dir>/<table name>.csv with a header row
+type CSV struct {
+	Dir string
+}
+
+func (c CSV) Write(ctx context.Context, table Table) error {
+	return writeFile(c.Dir, table.Name+".csv", func(w io.Writer) error {
+		cw := csv.NewWriter(w)
+		if err := cw.Write(table.Header); err != nil {
+			return err
+		}
+		for _, row := range table.Rows {
+			record := make([]string, len(row))
+			for i, value := range row {
+				record[i] = cellString(value)
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	})
+}
+
+// JSON writes every table to <dir>/<table name>.json as an array of objects
+// keyed by column name. Numbers keep their full precision.
+type JSON struct {
+	Dir string
+}
+
+func (j JSON) Write(ctx context.Context, table Table) error {
+
+	objects := make([]map[string]interface{}, len(table.Rows))
+	for i, row := range table.Rows {
+		object := make(map[string]interface{}, len(table.Header))
+		for c, column := range table.Header {
+			if c < len(row) {
+				object[column] = row[c]
+			}
+		}
+		objects[i] = object
+	}
+
+	return writeFile(j.Dir, table.Name+".json", func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(objects)
+	})
+}
+
+// writeFile replaces a file in the given directory atomically
+func writeFile(dir, name string, write func(w io.Writer) error) error {
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return errors.Wrap(err, "unable to create output directory")
+	}
+
+	tmp, err := ioutil.TempFile(dir, name+".*.tmp")
+	if err != nil {
+		return errors.Wrap(err, "unable to create output file")
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return errors.Wrapf(err, "unable to write %s", name)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return errors.Wrapf(err, "unable to write %s", name)
+	}
+
+	path := filepath.Join(dir, name)
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return errors.Wrapf(err, "unable to write %s", name)
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package sink
+
+import (
+	"context"
+
+	"github.com/jvalecillos/jira-metrics/pkg/helper"
+	"github.com/pkg/errors"
+)
+
+// Sheet writes every table to a range of a Google Spreadsheet, replacing the
+// values in the range. The header is expected in the sheet already, so the
+// range should start below it.
+type Sheet struct {
+	Helper        helper.SpreadSheetHelper
+	SpreadSheetID string
+	// Range returns the range a table is written to, empty when the table
+	// isn't configured for this sheet
+	Range func(tableName string) string
+}
+
+func (s Sheet) Write(ctx context.Context, table Table) error {
+
+	writeRange := s.Range(table.Name)
+	if writeRange == "" {
+		return errors.Errorf("no Google Sheets range configured for table %s", table.Name)
+	}
+
+	if _, err := s.Helper.Clear(ctx, s.SpreadSheetID, writeRange); err != nil {
+		return errors.Wrapf(err, "error clearing previous rows of %s", table.Name)
+	}
+
+	if len(table.Rows) == 0 {
+		return nil
+	}
+
+	if _, err := s.Helper.Append(ctx, s.SpreadSheetID, writeRange, table.Rows); err != nil {
+		return errors.Wrapf(err, "error writing %s in GoogleSheets", table.Name)
+	}
+
+	return nil
+}
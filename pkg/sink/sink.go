@@ -0,0 +1,62 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jvalecillos/jira-metrics/pkg/googlesheets"
+	"github.com/pkg/errors"
+)
+
+const (
+	TypeSheet = "sheet"
+	TypeCSV   = "csv"
+	TypeJSON  = "json"
+)
+
+// Table is a set of rows along with their column names. Name identifies the
+// table in every sink, e.g. as a file name.
+type Table struct {
+	Name   string
+	Header []string
+	Rows   googlesheets.GoogleSheetValues
+}
+
+// Sink is a destination the tables of the reports are written to. Writing a
+// table replaces the previous version of the same table.
+type Sink interface {
+	Write(ctx context.Context, table Table) error
+}
+
+// Multi writes tables to all the given sinks in order
+type Multi []Sink
+
+func (m Multi) Write(ctx context.Context, table Table) error {
+	for _, s := range m {
+		if err := s.Write(ctx, table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateTypes checks that all the given sink types are known
+func ValidateTypes(types []string) error {
+	for _, t := range types {
+		switch strings.ToLower(t) {
+		case TypeSheet, TypeCSV, TypeJSON:
+		default:
+			return errors.Errorf("unknown sink %q, expected %s, %s or %s", t, TypeSheet, TypeCSV, TypeJSON)
+		}
+	}
+	return nil
+}
+
+// cellString formats a cell for the file sinks
+func cellString(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}